@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runConfig реализует подкоманду config: print показывает действующую конфигурацию
+func runConfig(args []string) error {
+	flags := flag.NewFlagSet("config", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bot config print")
+		fmt.Fprintln(flags.Output(), "  print  show the effective configuration with secrets redacted and the origin of each value")
+	}
+	flags.Parse(args)
+
+	if flags.Arg(0) != "print" {
+		flags.Usage()
+		return fmt.Errorf("unknown config command %q", flags.Arg(0))
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	profile := cfg.Profile
+	if profile == "" {
+		profile = "-"
+	}
+	files := strings.Join(cfg.ConfigFiles, ", ")
+	if files == "" {
+		files = "-"
+	}
+	fmt.Printf("Profile: %s\nFiles:   %s\n\n", profile, files)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tORIGIN")
+	for _, entry := range cfg.Entries() {
+		value := entry.Value
+		if value == "" {
+			value = `""`
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Key, value, entry.Origin)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/andrei/goBot/internal/storage"
+)
+
+// runExport реализует подкоманду export: выгружает подписчиков, журнал статей,
+// отзывы и словари всех чатов или одного чата
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "json", "output format: json or csv")
+	out := flags.String("out", "", "output file for json, directory for csv")
+	chatID := flags.Int64("chat", 0, "export only data tied to this chat ID")
+	flags.Parse(args)
+
+	if *out == "" {
+		flags.Usage()
+		return fmt.Errorf("-out is required")
+	}
+
+	store, err := openDataStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	dump, err := store.Export(*chatID)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "json":
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if err := dump.WriteJSON(file); err != nil {
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	case "csv":
+		if err := dump.WriteCSV(*out); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q, expected json or csv", *format)
+	}
+
+	fmt.Printf("Exported %d users, %d articles, %d feedback entries and %d vocabulary terms to %s\n",
+		len(dump.Users), len(dump.Articles), len(dump.Feedback), len(dump.Vocabulary), *out)
+	return nil
+}
+
+// runImport реализует подкоманду import: загружает выгрузку, обновляя существующие записи
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "json", "input format: json or csv")
+	in := flags.String("in", "", "input file for json, directory for csv")
+	flags.Parse(args)
+
+	if *in == "" {
+		flags.Usage()
+		return fmt.Errorf("-in is required")
+	}
+
+	var dump *storage.Dump
+	switch *format {
+	case "json":
+		file, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if dump, err = storage.ReadDumpJSON(file); err != nil {
+			return err
+		}
+	case "csv":
+		var err error
+		if dump, err = storage.ReadDumpCSV(*in); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q, expected json or csv", *format)
+	}
+
+	store, err := openDataStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.Import(dump); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d users, %d articles, %d feedback entries and %d vocabulary terms from %s\n",
+		len(dump.Users), len(dump.Articles), len(dump.Feedback), len(dump.Vocabulary), *in)
+	return nil
+}
+
+// openDataStore открывает хранилище из конфигурации без перехода на хранилище в памяти:
+// выгрузка пустого хранилища вместо настоящих данных была бы ошибкой
+func openDataStore() (storage.UserStore, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := storageOptions(cfg)
+	opts.Fallback = storage.FallbackFail
+
+	return storage.Open(opts, logger)
+}
@@ -2,111 +2,302 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"os/signal"
-	"sync"
-	"syscall"
+	"strings"
+	"time"
 
 	"github.com/andrei/goBot/internal/config"
+	"github.com/andrei/goBot/internal/httpclient"
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/metrics"
 	"github.com/andrei/goBot/internal/news"
+	"github.com/andrei/goBot/internal/storage"
 	"github.com/andrei/goBot/internal/summarizer"
 	"github.com/andrei/goBot/internal/telegram"
-	"github.com/robfig/cron/v3"
+	"github.com/andrei/goBot/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// commands — подкоманды: имя и обработчик аргументов после него
+var commands = map[string]func(args []string) error{
+	"run":     runBot,
+	"once":    runOnce,
+	"preview": runPreview,
+	"users":   runUsers,
+	"migrate": runMigrate,
+	"export":  runExport,
+	"import":  runImport,
+	"config":  runConfig,
+}
+
+// tracer создаёт корневые спаны запусков конвейера
+var tracer = otel.Tracer("github.com/andrei/goBot/cmd/bot")
+
+// logLevel — уровень журнала, который меняется при перезагрузке конфигурации
+var logLevel = new(slog.LevelVar)
+
+// logger — общий журнал бота и подкоманд. Пишет в stderr, поэтому stdout остаётся для вывода команд.
+// До загрузки конфигурации используется текстовый формат.
+var logger = newLogger(logging.FormatText)
+
+const usage = `Usage: bot [command] [arguments]
+
+Commands:
+  run       start the bot and deliver news on schedule (default)
+  once      fetch, summarize and deliver one article, then exit
+  preview   fetch and summarize one article and print the message without sending it
+  users     list or count subscribers
+  migrate   show or apply database schema migrations
+  export    export subscriber data to JSON or CSV
+  import    import subscriber data from JSON or CSV
+  config    print the effective configuration
+
+Run "bot <command> -h" for the arguments of a command.
+`
+
 func main() {
-	// Инициализация логгера
-	logger := log.New(os.Stdout, "TechNewsBot: ", log.LstdFlags)
+	// Без аргументов запускается бот, как и раньше
+	name, args := "run", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
 
-	// Загрузка конфигурации
-	cfg, err := config.LoadConfig()
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		fmt.Print(usage)
+		return
+	}
+
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	if err := command(args); err != nil {
+		logger.Error("Command failed", "command", name, logging.Error, err)
+		os.Exit(1)
+	}
+}
+
+// newLogger создаёт журнал в формате format и делает его журналом по умолчанию для пакетов,
+// которые пишут через slog напрямую
+func newLogger(format string) *slog.Logger {
+	l, err := logging.New(os.Stderr, format, logLevel)
 	if err != nil {
-		logger.Fatalf("Failed to load config: %v", err)
+		l, _ = logging.New(os.Stderr, logging.FormatText, logLevel)
 	}
+	slog.SetDefault(l)
+
+	return l
+}
 
-	// Инициализация компонентов
-	newsClient := news.NewClient(cfg.NewsAPIKey)
-	summarizer := summarizer.NewSummarizer(cfg.OpenAIAPIKey)
-	bot, err := telegram.NewBot(cfg.TelegramBotToken, logger)
+// loadConfig загружает конфигурацию для всех подкоманд и настраивает по ней журнал.
+// required — ключи API, которые нужны подкоманде, см. config.LoadConfig.
+func loadConfig(required ...string) (*config.Config, error) {
+	cfg, err := config.LoadConfig(required...)
 	if err != nil {
-		logger.Fatalf("Failed to create Telegram bot: %v", err)
-	}
-	
-	// Отложенное закрытие ресурсов
-	defer func() {
-		// Закрываем пользовательскую базу данных
-		users := bot.Users()
-		if users != nil {
-			if closer, ok := interface{}(users).(interface{ Close() error }); ok {
-				if err := closer.Close(); err != nil {
-					logger.Printf("Error closing users database: %v", err)
-				}
-			}
-		}
-	}()
-
-	// Создание контекста с отменой
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// WaitGroup для горутин
-	var wg sync.WaitGroup
-
-	// Запуск обработки сообщений бота
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		bot.Start()
-	}()
-
-	// Инициализация планировщика
-	c := cron.New()
-	
-	// Добавление задачи в планировщик
-	_, err = c.AddFunc(cfg.ScheduleTime, func() {
-		if err := processNews(ctx, newsClient, summarizer, bot, cfg, logger); err != nil {
-			logger.Printf("Error processing news: %v", err)
-		}
-	})
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Уровень и формат уже проверены при загрузке
+	level, _ := logging.ParseLevel(cfg.LogLevel)
+	logLevel.Set(level)
+	logger = newLogger(cfg.LogFormat)
+
+	if len(cfg.ConfigFiles) > 0 {
+		logger.Info("Configuration loaded", "files", strings.Join(cfg.ConfigFiles, ", "))
+	}
+
+	return cfg, nil
+}
+
+// storageOptions возвращает настройки хранилища подписчиков из конфигурации
+func storageOptions(cfg *config.Config) storage.Options {
+	return storage.Options{
+		Backend:      cfg.StorageBackend,
+		SQLitePath:   cfg.DBPath,
+		PostgresDSN:  cfg.PostgresDSN,
+		SnapshotPath: cfg.MemorySnapshotPath,
+		Fallback:     cfg.StorageFallback,
+	}
+}
+
+// runPipeline выполняет один запуск конвейера: даёт ему run_id для журнала,
+// открывает корневой спан трассы и записывает метрики запуска
+func runPipeline(ctx context.Context, svc *services, bot *telegram.Bot, logger *slog.Logger) error {
+	runID := logging.NewRunID()
+	ctx = logging.With(ctx, logging.RunID, runID)
+	ctx, span := tracer.Start(ctx, "pipeline.run", trace.WithAttributes(attribute.String(logging.RunID, runID)))
+
+	start := time.Now()
+	err := processNews(ctx, svc.news, svc.summarizer, bot, svc.cfg, logger)
+	metrics.ObservePipeline(start, err)
+	tracing.End(span, err)
+	if err != nil {
+		logger.ErrorContext(ctx, "Pipeline run failed", "duration", time.Since(start), logging.Error, err)
+	}
+
+	return err
+}
+
+// setupTracing включает экспорт спанов по конфигурации и возвращает функцию,
+// которая при завершении отправляет накопленные спаны
+func setupTracing(cfg *config.Config) func() {
+	shutdown, err := tracing.Setup(context.Background(), cfg.TracingExporter, cfg.TracingEndpoint)
 	if err != nil {
-		logger.Fatalf("Failed to schedule task: %v", err)
+		logger.Warn("Tracing is disabled", logging.Error, err)
+		return func() {}
 	}
 
-	// Запуск планировщика
-	c.Start()
-	logger.Printf("Bot started. Scheduled to run at %s", cfg.ScheduleTime)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			logger.Warn("Error flushing traces", logging.Error, err)
+		}
+	}
+}
 
-	// Обработка сигналов завершения
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
+// sourceHTTPOptions возвращает настройки запросов к источникам новостей из конфигурации
+func sourceHTTPOptions(cfg *config.Config) httpclient.Options {
+	return httpclient.Options{
+		Timeout:          cfg.SourceTimeout,
+		MaxRetries:       cfg.SourceMaxRetries,
+		BaseDelay:        cfg.SourceRetryBaseDelay,
+		MaxDelay:         cfg.SourceRetryMaxDelay,
+		MinInterval:      cfg.SourceMinInterval,
+		BreakerThreshold: cfg.SourceBreakerThreshold,
+		BreakerCooldown:  cfg.SourceBreakerCooldown,
+	}
+}
+
+// newsOptions возвращает настройки клиента NewsAPI из конфигурации
+func newsOptions(cfg *config.Config) news.Options {
+	opts := news.Options{
+		Query: news.Query{
+			Endpoint:       cfg.NewsEndpoint,
+			Q:              cfg.NewsQuery,
+			Domains:        cfg.NewsDomains,
+			ExcludeDomains: cfg.NewsExcludeDomains,
+			SortBy:         cfg.NewsSortBy,
+			Category:       cfg.NewsCategory,
+			Country:        cfg.NewsCountry,
+			PageSize:       cfg.NewsPageSize,
+			Pages:          cfg.NewsPages,
+			From:           cfg.NewsFrom,
+			To:             cfg.NewsTo,
+		},
+		ClusterThreshold: cfg.NewsClusterThreshold,
+		Scoring: news.ScoringOptions{
+			Weights: map[string]float64{
+				news.SignalFreshness:    cfg.ScoringFreshnessWeight,
+				news.SignalCompleteness: cfg.ScoringCompletenessWeight,
+				news.SignalSource:       cfg.ScoringSourceWeight,
+				news.SignalTopics:       cfg.ScoringTopicsWeight,
+				news.SignalCoverage:     cfg.ScoringCoverageWeight,
+				news.SignalFeedback:     cfg.ScoringFeedbackWeight,
+				news.SignalBlocklist:    cfg.ScoringBlocklistWeight,
+			},
+			FreshnessHalfLife: cfg.ScoringFreshnessHalfLife,
+			Sources:           cfg.ScoringSources,
+			Keywords:          cfg.ScoringKeywords,
+			Blocklist:         cfg.ScoringBlocklist,
+		},
+		Filters: news.FilterOptions{
+			AllowDomains:     cfg.FilterAllowDomains,
+			BlockDomains:     cfg.FilterBlockDomains,
+			TitlePatterns:    cfg.FilterTitlePatterns,
+			MinContentLength: cfg.FilterMinContentLength,
+			MaxAge:           cfg.FilterMaxAge,
+			DetectLanguage:   cfg.FilterDetectLanguage,
+		},
+	}
+	if cfg.ScoringLLMEnabled {
+		opts.Ranking = news.RankingOptions{Shortlist: cfg.ScoringLLMShortlist, Weight: cfg.ScoringLLMWeight}
+	}
 
-	logger.Println("Shutting down...")
-	c.Stop()
-	cancel()
-	wg.Wait()
+	return opts
 }
 
-func processNews(ctx context.Context, newsClient *news.Client, summarizer *summarizer.Summarizer, bot *telegram.Bot, cfg *config.Config, logger *log.Logger) error {
+func processNews(ctx context.Context, newsClient *news.Client, summarizer *summarizer.Summarizer, bot *telegram.Bot, cfg *config.Config, logger *slog.Logger) error {
+	// Учитываем отзывы подписчиков при выборе статьи
+	newsClient.SetPreferences(bot.Users().Preferences())
+
+	// В режиме модерации статью сначала одобряют редакторы
+	if cfg.ModerationEnabled {
+		return moderateNews(ctx, newsClient, summarizer, bot, cfg, logger)
+	}
+
 	// Получение последней новости
-	article, err := newsClient.FetchLatestTechNews(cfg.NewsLanguage)
+	article, err := newsClient.FetchLatestTechNews(ctx, cfg.NewsLanguage)
 	if err != nil {
 		return fmt.Errorf("failed to fetch news: %w", err)
 	}
 
 	// Обработка статьи через ChatGPT
-	summary, err := summarizer.ProcessArticle(ctx, article)
+	summary, err := summarizeArticle(summarizer, cfg, logger)(ctx, article)
 	if err != nil {
 		return fmt.Errorf("failed to process article: %w", err)
 	}
 
 	// Отправка сообщения в Telegram
-	if err := bot.SendArticleSummary(article, summary); err != nil {
+	if err := bot.SendArticleSummary(ctx, article, summary); err != nil {
 		return fmt.Errorf("failed to send message: %w", err)
 	}
 
-	logger.Printf("Successfully processed and sent article: %s", article.Title)
+	logger.InfoContext(ctx, "Successfully processed and sent article", logging.ArticleURL, article.URL, "title", article.Title)
 	return nil
-} 
\ No newline at end of file
+}
+
+func moderateNews(ctx context.Context, newsClient *news.Client, summarizer *summarizer.Summarizer, bot *telegram.Bot, cfg *config.Config, logger *slog.Logger) error {
+	candidates, err := newsClient.FetchCandidates(ctx, cfg.NewsLanguage, cfg.ModerationCandidates)
+	if err != nil {
+		return fmt.Errorf("failed to fetch news: %w", err)
+	}
+
+	moderation := telegram.ModerationConfig{
+		EditorsChatID: cfg.EditorsChatID,
+		Timeout:       cfg.ModerationTimeout,
+	}
+
+	logger.InfoContext(ctx, "Sending candidates to editors chat", "candidates", len(candidates), logging.ChatID, cfg.EditorsChatID)
+	if err := bot.Moderate(ctx, moderation, candidates, summarizeArticle(summarizer, cfg, logger)); err != nil {
+		if errors.Is(err, telegram.ErrNothingApproved) {
+			logger.InfoContext(ctx, "Editors skipped all candidates, nothing was sent")
+			return nil
+		}
+		return fmt.Errorf("failed to moderate news: %w", err)
+	}
+
+	return nil
+}
+
+// summarizeArticle возвращает функцию обработки статьи через ChatGPT, которая при
+// включённой викторине добавляет к сводке вопросы по статье
+func summarizeArticle(s *summarizer.Summarizer, cfg *config.Config, logger *slog.Logger) telegram.SummarizeFunc {
+	return func(ctx context.Context, article *news.Article) (*summarizer.Summary, error) {
+		ctx = logging.With(ctx, logging.ArticleURL, article.URL)
+
+		summary, err := s.ProcessArticle(ctx, article)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.QuizEnabled {
+			// Без викторины статья всё равно отправляется
+			quiz, err := s.GenerateQuiz(ctx, article, cfg.QuizQuestions)
+			if err != nil {
+				logger.WarnContext(ctx, "Error generating quiz", logging.Error, err)
+			} else {
+				summary.Quiz = quiz
+			}
+		}
+
+		return summary, nil
+	}
+}
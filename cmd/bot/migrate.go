@@ -0,0 +1,107 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/andrei/goBot/internal/config"
+	"github.com/andrei/goBot/internal/storage"
+)
+
+// runMigrate реализует подкоманду migrate: status показывает состояние схемы, up применяет миграции
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bot migrate [status|up]")
+		fmt.Fprintln(flags.Output(), "  status  list migrations and whether they are applied (default)")
+		fmt.Fprintln(flags.Output(), "  up      apply all pending migrations")
+	}
+	flags.Parse(args)
+
+	action := "status"
+	if flags.NArg() > 0 {
+		action = flags.Arg(0)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, migrator, name, err := openMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Printf("Database %s is up to date\n", name)
+		}
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, status := range statuses {
+			state, appliedAt := "pending", "-"
+			if status.Applied {
+				state, appliedAt = "applied", status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown migrate command %q", action)
+	}
+
+	return nil
+}
+
+// openMigrator подключается к базе выбранного в конфигурации хранилища
+// и возвращает мигратор для неё вместе с именем базы для вывода
+func openMigrator(cfg *config.Config) (*sql.DB, *storage.Migrator, string, error) {
+	var db *sql.DB
+	var newMigrator func(*sql.DB) (*storage.Migrator, error)
+	var name string
+	var err error
+
+	switch cfg.StorageBackend {
+	case storage.BackendPostgres:
+		db, err = storage.OpenPostgres(cfg.PostgresDSN)
+		newMigrator, name = storage.NewPostgresMigrator, "PostgreSQL"
+	case storage.BackendMemory:
+		return nil, nil, "", fmt.Errorf("in-memory storage has no schema to migrate")
+	default:
+		db, err = storage.OpenSQLite(cfg.DBPath)
+		newMigrator, name = storage.NewSQLiteMigrator, cfg.DBPath
+	}
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	migrator, err := newMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, "", err
+	}
+
+	return db, migrator, name, nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/andrei/goBot/internal/config"
+	"github.com/andrei/goBot/internal/httpclient"
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/news"
+	"github.com/andrei/goBot/internal/summarizer"
+	"github.com/andrei/goBot/internal/telegram"
+	"github.com/robfig/cron/v3"
+)
+
+// restartKeys — настройки, которые читаются только при запуске бота
+var restartKeys = map[string]bool{
+	"telegram.token":               true,
+	"log.format":                   true,
+	"tracing.exporter":             true,
+	"tracing.endpoint":             true,
+	"server.addr":                  true,
+	"storage.backend":              true,
+	"storage.sqlite_path":          true,
+	"storage.postgres_dsn":         true,
+	"storage.fallback":             true,
+	"storage.memory_snapshot_path": true,
+}
+
+// services — конфигурация вместе с зависящими от неё клиентами.
+// При перезагрузке конфигурации заменяется целиком, поэтому задача,
+// уже начавшая работу, до конца использует прежние настройки.
+type services struct {
+	cfg        *config.Config
+	http       *httpclient.Client
+	quota      *httpclient.Budget
+	news       *news.Client
+	summarizer *summarizer.Summarizer
+}
+
+func newServices(cfg *config.Config) (*services, error) {
+	quota, err := newQuota(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &services{
+		cfg:        cfg,
+		http:       httpclient.New(sourceHTTPOptions(cfg), logger),
+		quota:      quota,
+		summarizer: summarizer.NewSummarizer(cfg.OpenAIAPIKey, logger),
+	}
+	s.http.SetBudget(news.APIHost, s.quota)
+	s.news = newNewsClient(cfg, s.http, s.quota)
+	s.news.SetRanker(s.summarizer)
+
+	return s, nil
+}
+
+// with возвращает services для новой конфигурации, пересоздавая только клиентов,
+// чьи ключи API, параметры или настройки запросов изменились. Новый HTTP-клиент начинает
+// с замкнутым автоматом защиты.
+func (s *services) with(cfg *config.Config) *services {
+	next := &services{cfg: cfg, http: s.http, quota: s.quota, news: s.news, summarizer: s.summarizer}
+
+	httpChanged := sourceHTTPOptions(cfg) != sourceHTTPOptions(s.cfg)
+	quotaChanged := cfg.NewsAPIDailyQuota != s.cfg.NewsAPIDailyQuota || cfg.NewsAPIQuotaPath != s.cfg.NewsAPIQuotaPath
+	if httpChanged {
+		next.http = httpclient.New(sourceHTTPOptions(cfg), logger)
+	}
+	if quotaChanged {
+		if quota, err := newQuota(cfg); err != nil {
+			logger.Error("Error opening NewsAPI quota, keeping previous quota", logging.Error, err)
+			quotaChanged = false
+		} else {
+			next.quota = quota
+		}
+	}
+	if httpChanged || quotaChanged {
+		next.http.SetBudget(news.APIHost, next.quota)
+	}
+
+	if cfg.OpenAIAPIKey != s.cfg.OpenAIAPIKey {
+		next.summarizer = summarizer.NewSummarizer(cfg.OpenAIAPIKey, logger)
+	}
+	// Лучшие статьи оценивает та же модель, что составляет сводки, поэтому с новым
+	// клиентом OpenAI пересоздаётся и клиент NewsAPI
+	if cfg.NewsAPIKey != s.cfg.NewsAPIKey || httpChanged || quotaChanged || cfg.SourceCacheTTL != s.cfg.SourceCacheTTL ||
+		!reflect.DeepEqual(newsOptions(cfg), newsOptions(s.cfg)) || next.summarizer != s.summarizer {
+		next.news = newNewsClient(cfg, next.http, next.quota)
+		next.news.SetRanker(next.summarizer)
+	}
+
+	return next
+}
+
+// newNewsClient создаёт клиент NewsAPI, запросы которого проходят через кэш ответов
+func newNewsClient(cfg *config.Config, client *httpclient.Client, quota *httpclient.Budget) *news.Client {
+	cache := httpclient.NewCache(client, cfg.SourceCacheDir, cfg.SourceCacheTTL, logger)
+	return news.NewClient(cfg.NewsAPIKey, newsOptions(cfg), cache, quota, logger)
+}
+
+// newQuota создаёт дневной бюджет запросов к NewsAPI. Расход сохраняется в файле
+// cfg.NewsAPIQuotaPath и не обнуляется при перезапуске. Если файл недоступен, бот
+// не запускается: иначе после перезапуска квота молча считалась бы с нуля.
+func newQuota(cfg *config.Config) (*httpclient.Budget, error) {
+	quota, err := httpclient.NewBudget(cfg.NewsAPIDailyQuota, cfg.NewsAPIQuotaPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening NewsAPI quota (set NEWS_API_QUOTA_PATH to a writable file, or to an empty string to keep usage in memory): %w", err)
+	}
+
+	return quota, nil
+}
+
+// scheduler регистрирует задачи бота в cron и перерегистрирует их при смене расписаний
+type scheduler struct {
+	ctx     context.Context
+	cron    *cron.Cron
+	bot     *telegram.Bot
+	logger  *slog.Logger
+	current atomic.Pointer[services]
+
+	// Пробный режим включён флагом -dry-run и остаётся включённым при перезагрузке
+	forceDryRun bool
+
+	mu   sync.Mutex
+	jobs []cron.EntryID
+
+	// newsRunning не даёт запуститься следующей рассылке, пока предыдущая ждёт
+	// решения редакторов: модерация может длиться дольше интервала расписания
+	newsRunning atomic.Bool
+}
+
+func newScheduler(ctx context.Context, c *cron.Cron, bot *telegram.Bot, cfg *config.Config, logger *slog.Logger) (*scheduler, error) {
+	s := &scheduler{ctx: ctx, cron: c, bot: bot, logger: logger}
+	svc, err := newServices(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.current.Store(svc)
+
+	if err := s.schedule(cfg); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// schedule заменяет задачи в cron задачами по расписаниям cfg
+func (s *scheduler) schedule(cfg *config.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range s.jobs {
+		s.cron.Remove(id)
+	}
+	s.jobs = nil
+
+	id, err := s.cron.AddFunc(cfg.ScheduleTime, func() {
+		if !s.newsRunning.CompareAndSwap(false, true) {
+			s.logger.Warn("Previous news run is still in progress, skipping this run")
+			return
+		}
+		defer s.newsRunning.Store(false)
+
+		// Ошибка уже записана в журнал вместе с run_id
+		runPipeline(s.ctx, s.current.Load(), s.bot, s.logger)
+	})
+	if err != nil {
+		return err
+	}
+	s.jobs = append(s.jobs, id)
+
+	// Напоминания о повторении терминов из личных словарей
+	if cfg.ReviewSchedule != "" {
+		id, err = s.cron.AddFunc(cfg.ReviewSchedule, s.bot.SendDueReviews)
+		if err != nil {
+			return err
+		}
+		s.jobs = append(s.jobs, id)
+	}
+
+	return nil
+}
+
+// reload применяет новую конфигурацию. Ключи, которые читаются только при запуске,
+// вступят в силу после перезапуска.
+func (s *scheduler) reload(cfg *config.Config) {
+	if s.forceDryRun && !cfg.DryRun {
+		forced := *cfg
+		forced.DryRun = true
+		cfg = &forced
+	}
+
+	prev := s.current.Load()
+	changed := config.Changed(prev.cfg, cfg)
+	if len(changed) == 0 {
+		return
+	}
+
+	// Расписания уже проверены при загрузке, поэтому ошибка здесь не ожидается
+	if cfg.ScheduleTime != prev.cfg.ScheduleTime || cfg.ReviewSchedule != prev.cfg.ReviewSchedule {
+		if err := s.schedule(cfg); err != nil {
+			s.logger.Error("Error rescheduling jobs, keeping previous configuration", logging.Error, err)
+			if err := s.schedule(prev.cfg); err != nil {
+				s.logger.Error("Error restoring previous schedule", logging.Error, err)
+			}
+			return
+		}
+	}
+
+	s.current.Store(prev.with(cfg))
+	if level, err := logging.ParseLevel(cfg.LogLevel); err == nil {
+		logLevel.Set(level)
+	}
+	if cfg.DryRun != prev.cfg.DryRun || cfg.DryRunOutput != prev.cfg.DryRunOutput ||
+		!reflect.DeepEqual(cfg.DryRunAllowlist, prev.cfg.DryRunAllowlist) {
+		setDryRun(s.bot, cfg)
+	}
+
+	var restart []string
+	for _, key := range changed {
+		if restartKeys[key] {
+			restart = append(restart, key)
+		}
+	}
+
+	s.logger.Info("Configuration reloaded", "changed", strings.Join(changed, ", "))
+	if len(restart) > 0 {
+		s.logger.Warn("Some settings take effect only after restart", "keys", strings.Join(restart, ", "))
+	}
+}
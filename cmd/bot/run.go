@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/andrei/goBot/internal/config"
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/metrics"
+	"github.com/andrei/goBot/internal/news"
+	"github.com/andrei/goBot/internal/storage"
+	"github.com/andrei/goBot/internal/telegram"
+	"github.com/robfig/cron/v3"
+)
+
+// runBot реализует подкоманду run: бот обрабатывает сообщения и рассылает новости по расписанию
+func runBot(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "write broadcasts to the dry run output instead of sending them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bot run [-dry-run]")
+		fmt.Fprintln(flags.Output(), "  start the bot and deliver news on schedule until SIGINT or SIGTERM")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	// Загрузка конфигурации
+	cfg, err := loadConfig(config.TelegramToken, config.NewsAPIKey, config.OpenAIAPIKey)
+	if err != nil {
+		return err
+	}
+	cfg.DryRun = cfg.DryRun || *dryRun
+	defer setupTracing(cfg)()
+
+	// Инициализация компонентов
+	users, bot, err := openBot(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Отложенное закрытие ресурсов
+	defer func() {
+		// Закрываем пользовательскую базу данных
+		if err := users.Close(); err != nil {
+			logger.Error("Error closing users database", logging.Error, err)
+		}
+	}()
+
+	// Создание контекста с отменой
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// WaitGroup для горутин
+	var wg sync.WaitGroup
+
+	// Метрики и проверки состояния
+	if cfg.HTTPAddr != "" {
+		metrics.RegisterSubscribers(func() map[string]int {
+			counts := make(map[string]int)
+			for _, target := range users.GetTargets() {
+				counts[target.Type]++
+			}
+			return counts
+		})
+
+		checks := map[string]metrics.Check{
+			"telegram": bot.Ping,
+			"storage":  users.Ping,
+		}
+		go func() {
+			if err := metrics.Serve(ctx, cfg.HTTPAddr, checks, logger); err != nil {
+				logger.Error("Error running metrics server", logging.Error, err)
+			}
+		}()
+	}
+
+	// Запуск обработки сообщений бота
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		bot.Start()
+	}()
+
+	// Инициализация планировщика
+	c := cron.New()
+	jobs, err := newScheduler(ctx, c, bot, cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to schedule task: %w", err)
+	}
+	jobs.forceDryRun = *dryRun
+
+	// Перезагрузка конфигурации при изменении файлов
+	watching := config.Watch(cfg, jobs.reload, func(err error) {
+		logger.Error("Configuration reload rejected, keeping previous configuration", logging.Error, err)
+	})
+	if watching {
+		logger.Info("Watching configuration files for changes", "files", strings.Join(cfg.ConfigFiles, ", "))
+	}
+
+	// Запуск планировщика
+	c.Start()
+	logger.Info("Bot started", "schedule", cfg.ScheduleTime)
+
+	// Обработка сигналов завершения
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	logger.Info("Shutting down")
+	c.Stop()
+	cancel()
+	wg.Wait()
+
+	return nil
+}
+
+// runOnce реализует подкоманду once: один запуск конвейера для внешнего планировщика
+func runOnce(args []string) error {
+	flags := flag.NewFlagSet("once", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "write the broadcast to the dry run output instead of sending it")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bot once [-dry-run]")
+		fmt.Fprintln(flags.Output(), "  fetch, summarize and deliver one article to all subscribers, then exit")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cfg, err := loadConfig(config.TelegramToken, config.NewsAPIKey, config.OpenAIAPIKey)
+	if err != nil {
+		return err
+	}
+	cfg.DryRun = cfg.DryRun || *dryRun
+	defer setupTracing(cfg)()
+
+	users, bot, err := openBot(cfg)
+	if err != nil {
+		return err
+	}
+	defer users.Close()
+
+	// Решения редакторов приходят как обновления бота, поэтому при модерации
+	// обработка сообщений работает, пока статья не будет одобрена или пропущена
+	if cfg.ModerationEnabled {
+		go bot.Start()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	svc, err := newServices(cfg)
+	if err != nil {
+		return err
+	}
+
+	return runPipeline(ctx, svc, bot, logger)
+}
+
+// runPreview реализует подкоманду preview: статья выбирается и обрабатывается
+// так же, как при рассылке, но сообщение выводится в stdout и никому не отправляется
+func runPreview(args []string) error {
+	flags := flag.NewFlagSet("preview", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bot preview [-scores]")
+		fmt.Fprintln(flags.Output(), "  fetch and summarize one article and print the rendered message without sending it")
+		flags.PrintDefaults()
+	}
+	scores := flags.Bool("scores", false, "also print the score breakdown of every candidate story")
+	flags.Parse(args)
+
+	// Превью ничего не отправляет в Telegram, поэтому токен бота ему не нужен
+	cfg, err := loadConfig(config.NewsAPIKey, config.OpenAIAPIKey)
+	if err != nil {
+		return err
+	}
+	defer setupTracing(cfg)()
+	svc, err := newServices(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Предпочтения аудитории влияют на выбор статьи, но без базы превью всё равно полезно
+	opts := storageOptions(cfg)
+	opts.Fallback = storage.FallbackFail
+	if users, err := storage.Open(opts, logger); err != nil {
+		logger.Warn("Audience preferences are not applied", logging.Error, err)
+	} else {
+		svc.news.SetPreferences(users.Preferences())
+		users.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ctx, span := tracer.Start(ctx, "pipeline.preview")
+	defer span.End()
+
+	// Статья та же, что выбрал бы FetchLatestTechNews: первый кандидат
+	candidates, err := svc.news.FetchCandidates(ctx, cfg.NewsLanguage, 0)
+	if err != nil {
+		return fmt.Errorf("failed to fetch news: %w", err)
+	}
+	article := candidates[0]
+
+	summary, err := summarizeArticle(svc.summarizer, cfg, logger)(ctx, article)
+	if err != nil {
+		return fmt.Errorf("failed to process article: %w", err)
+	}
+
+	fmt.Println(telegram.FormatMessage(article, summary))
+	if len(article.Coverage) > 0 {
+		fmt.Printf("\nAlso covered by: %s\n", strings.Join(article.Coverage, ", "))
+	}
+
+	for i, question := range summary.Quiz {
+		fmt.Printf("\nQuiz %d: %s\n", i+1, question.Question)
+		for j, option := range question.Options {
+			mark := " "
+			if j == question.Correct {
+				mark = "*"
+			}
+			fmt.Printf("  %s %s\n", mark, option)
+		}
+	}
+
+	if *scores {
+		printScores(candidates)
+	}
+
+	return nil
+}
+
+// printScores выводит оценки кандидатов с вкладом каждого признака
+func printScores(candidates []*news.Article) {
+	fmt.Println("\nScores:")
+	for i, article := range candidates {
+		fmt.Printf("\n%d. %s\n   %s\n", i+1, article.Title, article.URL)
+		if article.Score == nil {
+			continue
+		}
+		fmt.Printf("   total %.1f\n", article.Score.Total)
+		for _, signal := range article.Score.Signals {
+			fmt.Printf("   %-13s %5.2f × %-5g = %6.1f\n", signal.Name, signal.Value, signal.Weight, signal.Points())
+			if signal.Note != "" {
+				fmt.Printf("   %-13s %s\n", "", signal.Note)
+			}
+		}
+	}
+}
+
+// openBot открывает хранилище подписчиков и подключает бота к Telegram
+func openBot(cfg *config.Config) (storage.UserStore, *telegram.Bot, error) {
+	users, err := storage.Open(storageOptions(cfg), logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open users storage: %w", err)
+	}
+
+	bot, err := telegram.NewBot(cfg.TelegramBotToken, users, logger)
+	if err != nil {
+		users.Close()
+		return nil, nil, fmt.Errorf("failed to create Telegram bot: %w", err)
+	}
+	setDryRun(bot, cfg)
+
+	return users, bot, nil
+}
+
+// setDryRun включает или выключает пробный режим рассылки по конфигурации
+func setDryRun(bot *telegram.Bot, cfg *config.Config) {
+	if !cfg.DryRun {
+		bot.SetDryRun(nil)
+		return
+	}
+
+	bot.SetDryRun(&telegram.DryRunConfig{
+		Output:    cfg.DryRunOutput,
+		Allowlist: cfg.DryRunAllowlist,
+	})
+
+	output := cfg.DryRunOutput
+	if output == "" {
+		output = "stdout"
+	}
+	logger.Info("Dry run: broadcasts are written instead of sent", "output", output, "allowlist", cfg.DryRunAllowlist)
+}
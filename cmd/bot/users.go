@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andrei/goBot/internal/storage"
+)
+
+// runUsers реализует подкоманду users: list выводит подписчиков, count — их число по типам чатов
+func runUsers(args []string) error {
+	flags := flag.NewFlagSet("users", flag.ExitOnError)
+	all := flags.Bool("all", false, "include unsubscribed chats")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bot users list|count [-all]")
+		fmt.Fprintln(flags.Output(), "  list   list subscribed chats with their profiles")
+		fmt.Fprintln(flags.Output(), "  count  count subscribed chats by type")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	action := flags.Arg(0)
+	if action != "list" && action != "count" {
+		flags.Usage()
+		return fmt.Errorf("unknown users command %q", action)
+	}
+	// Флаги можно указать и после действия: users list -all
+	flags.Parse(flags.Args()[1:])
+
+	store, err := openDataStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	dump, err := store.Export(0)
+	if err != nil {
+		return err
+	}
+
+	var users []storage.UserRecord
+	for _, user := range dump.Users {
+		if user.Active || *all {
+			users = append(users, user)
+		}
+	}
+
+	if action == "count" {
+		return printUserCount(users)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAT ID\tTYPE\tNAME\tLANGUAGE\tACTIVE\tLAST SEEN\tSUBSCRIBED AT")
+	for _, user := range users {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\t%s\n", user.ChatID, user.ChatType, userName(user),
+			orDash(user.LanguageCode), user.Active, formatDate(user.LastSeen), formatDate(user.CreatedAt))
+	}
+
+	return w.Flush()
+}
+
+func printUserCount(users []storage.UserRecord) error {
+	byType := make(map[string]int)
+	for _, user := range users {
+		byType[user.ChatType]++
+	}
+
+	types := make([]string, 0, len(byType))
+	for chatType := range byType {
+		types = append(types, chatType)
+	}
+	sort.Strings(types)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, chatType := range types {
+		fmt.Fprintf(w, "%s\t%d\n", chatType, byType[chatType])
+	}
+	fmt.Fprintf(w, "total\t%d\n", len(users))
+
+	return w.Flush()
+}
+
+// userName возвращает название группы или канала либо имя и @username подписчика
+func userName(user storage.UserRecord) string {
+	if user.Title != "" {
+		return user.Title
+	}
+
+	name := user.FirstName
+	if user.LastName != "" {
+		name += " " + user.LastName
+	}
+	if user.Username != "" {
+		if name != "" {
+			name += " "
+		}
+		name += "@" + user.Username
+	}
+
+	return orDash(name)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
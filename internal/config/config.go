@@ -1,62 +1,637 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	TelegramBotToken string
-	NewsAPIKey       string
-	OpenAIAPIKey     string
-	NewsCategory     string
-	NewsLanguage     string
-	ScheduleTime     string
-}
-
-func LoadConfig() (*Config, error) {
-	// Настройка Viper для чтения из переменных окружения
-	viper.AutomaticEnv()
-
-	// Также попробуем прочитать из файла .env, если он существует
-	viper.SetConfigFile(".env")
-	viper.SetConfigType("env")
-	
-	// Чтение файла
-	if err := viper.ReadInConfig(); err != nil {
-		// Если файл не найден, продолжаем работу с переменными окружения
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			// Если ошибка не связана с отсутствием файла, логируем её,
-			// но продолжаем работу с переменными окружения
-			fmt.Printf("Warning: Error reading .env file: %v\n", err)
-		}
-	}
-
-	// Установка значений по умолчанию
-	viper.SetDefault("NEWS_CATEGORY", "technology")
-	viper.SetDefault("NEWS_LANGUAGE", "en")
-	viper.SetDefault("SCHEDULE_TIME", "0 9 * * *") // По умолчанию в 9:00 каждый день
-
-	// Проверка обязательных переменных
-	requiredEnvs := []string{
-		"TELEGRAM_BOT_TOKEN",
-		"NEWS_API_KEY",
-		"OPENAI_API_KEY",
-	}
-
-	for _, env := range requiredEnvs {
-		if !viper.IsSet(env) {
-			return nil, fmt.Errorf("required environment variable %s is not set", env)
-		}
-	}
-
-	return &Config{
-		TelegramBotToken: viper.GetString("TELEGRAM_BOT_TOKEN"),
-		NewsAPIKey:       viper.GetString("NEWS_API_KEY"),
-		OpenAIAPIKey:     viper.GetString("OPENAI_API_KEY"),
-		NewsCategory:     viper.GetString("NEWS_CATEGORY"),
-		NewsLanguage:     viper.GetString("NEWS_LANGUAGE"),
-		ScheduleTime:     viper.GetString("SCHEDULE_TIME"),
-	}, nil
-} 
\ No newline at end of file
+	TelegramBotToken string `config:"telegram.token"`
+	NewsAPIKey       string `config:"sources.newsapi.api_key"`
+	OpenAIAPIKey     string `config:"summarizer.api_key"`
+	NewsCategory     string `config:"sources.newsapi.category"`
+	NewsLanguage     string `config:"sources.newsapi.language"`
+	ScheduleTime     string `config:"schedules.news"`
+	ReviewSchedule   string `config:"schedules.review"`    // расписание повторения терминов; пустое значение отключает напоминания
+	DBPath           string `config:"storage.sqlite_path"` // путь к файлу базы данных SQLite
+
+	// Хранилище подписчиков: sqlite, postgres или memory
+	StorageBackend string `config:"storage.backend"`
+	// Строка подключения к PostgreSQL для STORAGE_BACKEND=postgres
+	PostgresDSN string `config:"storage.postgres_dsn"`
+	// Что делать, если база недоступна: fail — остановиться, memory — работать в памяти
+	StorageFallback string `config:"storage.fallback"`
+	// Файл снимка хранилища в памяти; пустое значение — данные теряются при перезапуске
+	MemorySnapshotPath string `config:"storage.memory_snapshot_path"`
+
+	// Викторина по статье: вопросы отправляются опросами после статьи
+	QuizEnabled   bool `config:"summarizer.quiz.enabled"`
+	QuizQuestions int  `config:"summarizer.quiz.questions"`
+
+	// Модерация: перед рассылкой статья отправляется в чат редакторов
+	ModerationEnabled    bool          `config:"telegram.moderation.enabled"`
+	EditorsChatID        int64         `config:"telegram.moderation.editors_chat_id"`
+	ModerationTimeout    time.Duration `config:"telegram.moderation.timeout"`
+	ModerationCandidates int           `config:"telegram.moderation.candidates"`
+
+	// Пробный режим: сообщения рассылки записываются в DryRunOutput (пустое значение — stdout)
+	// вместо отправки; чатам из DryRunAllowlist рассылка всё же отправляется
+	DryRun          bool    `config:"telegram.dry_run.enabled"`
+	DryRunOutput    string  `config:"telegram.dry_run.output"`
+	DryRunAllowlist []int64 `config:"telegram.dry_run.allowlist"`
+
+	// Запросы к источникам новостей: тайм-аут попытки, повторы с растущей паузой,
+	// минимальный интервал между запросами к хосту и автомат защиты
+	SourceTimeout          time.Duration `config:"sources.http.timeout"`
+	SourceMaxRetries       int           `config:"sources.http.max_retries"`
+	SourceRetryBaseDelay   time.Duration `config:"sources.http.retry_base_delay"`
+	SourceRetryMaxDelay    time.Duration `config:"sources.http.retry_max_delay"`
+	SourceMinInterval      time.Duration `config:"sources.http.min_interval"`
+	SourceBreakerThreshold int           `config:"sources.http.breaker_threshold"`
+	SourceBreakerCooldown  time.Duration `config:"sources.http.breaker_cooldown"`
+
+	// Кэш ответов источников: каталог (пустое значение — в памяти) и время, в течение
+	// которого ответ отдаётся без запроса; после него ответ перепроверяется условным запросом
+	SourceCacheDir string        `config:"sources.cache.dir"`
+	SourceCacheTTL time.Duration `config:"sources.cache.ttl"`
+	// Дневной бюджет запросов к NewsAPI, включая повторы; 0 снимает ограничение.
+	// Расход хранится в файле, чтобы не обнуляться при перезапуске и между запусками once;
+	// пустой путь — расход считается только в памяти.
+	NewsAPIDailyQuota int    `config:"sources.newsapi.daily_quota"`
+	NewsAPIQuotaPath  string `config:"sources.newsapi.quota_path"`
+
+	// Запрос статей к NewsAPI: метод everything или top-headlines, поисковый запрос,
+	// домены, сортировка, страна, размер и число страниц, период публикации
+	// относительно момента запроса. Category и Country действуют только для top-headlines,
+	// домены, сортировка, язык и период — только для everything.
+	NewsEndpoint       string        `config:"sources.newsapi.endpoint"`
+	NewsQuery          string        `config:"sources.newsapi.query"`
+	NewsDomains        []string      `config:"sources.newsapi.domains"`
+	NewsExcludeDomains []string      `config:"sources.newsapi.exclude_domains"`
+	NewsSortBy         string        `config:"sources.newsapi.sort_by"`
+	NewsCountry        string        `config:"sources.newsapi.country"`
+	NewsPageSize       int           `config:"sources.newsapi.page_size"`
+	NewsPages          int           `config:"sources.newsapi.pages"`
+	NewsFrom           time.Duration `config:"sources.newsapi.from"`
+	NewsTo             time.Duration `config:"sources.newsapi.to"`
+
+	// Сходство статей (оценка коэффициента Жаккара значимых слов заголовка и описания),
+	// начиная с которого они считаются одной историей; 0 отключает объединение
+	NewsClusterThreshold float64 `config:"sources.clustering.threshold"`
+
+	// Оценка статей: веса признаков, период полураспада свежести, репутация изданий
+	// (домен или название=значение от -1 до 1), темы и слова, опускающие статью вниз
+	ScoringFreshnessWeight    float64            `config:"scoring.weights.freshness"`
+	ScoringCompletenessWeight float64            `config:"scoring.weights.completeness"`
+	ScoringSourceWeight       float64            `config:"scoring.weights.source"`
+	ScoringTopicsWeight       float64            `config:"scoring.weights.topics"`
+	ScoringCoverageWeight     float64            `config:"scoring.weights.coverage"`
+	ScoringFeedbackWeight     float64            `config:"scoring.weights.feedback"`
+	ScoringBlocklistWeight    float64            `config:"scoring.weights.blocklist"`
+	ScoringFreshnessHalfLife  time.Duration      `config:"scoring.freshness_half_life"`
+	ScoringSources            map[string]float64 `config:"scoring.sources"`
+	ScoringKeywords           []string           `config:"scoring.keywords"`
+	ScoringBlocklist          []string           `config:"scoring.blocklist"`
+
+	// Оценка лучших историй языковой моделью: сколько историй оценивать одним запросом
+	// и вес её оценки в сумме признаков
+	ScoringLLMEnabled   bool    `config:"scoring.llm.enabled"`
+	ScoringLLMShortlist int     `config:"scoring.llm.shortlist"`
+	ScoringLLMWeight    float64 `config:"scoring.llm.weight"`
+
+	// Фильтры статей после получения: списки доменов, регулярные выражения для заголовков,
+	// минимальная длина текста, максимальный возраст и проверка языка
+	FilterAllowDomains     []string      `config:"filters.allow_domains"`
+	FilterBlockDomains     []string      `config:"filters.block_domains"`
+	FilterTitlePatterns    []string      `config:"filters.title_patterns"`
+	FilterMinContentLength int           `config:"filters.min_content_length"`
+	FilterMaxAge           time.Duration `config:"filters.max_age"`
+	FilterDetectLanguage   bool          `config:"filters.detect_language"`
+
+	// Адрес HTTP-сервера с /healthz, /readyz и /metrics; пустое значение отключает сервер.
+	// Авторизации нет, поэтому по умолчанию сервер выключен.
+	HTTPAddr string `config:"server.addr"`
+
+	// Журнал: уровень debug, info, warn или error и формат text или json
+	LogLevel  string `config:"log.level"`
+	LogFormat string `config:"log.format"`
+
+	// Трассировка: экспорт спанов в none, stdout или otlp; адрес коллектора OTLP/HTTP
+	// (пустое значение — OTEL_EXPORTER_OTLP_ENDPOINT или localhost:4318)
+	TracingExporter string `config:"tracing.exporter"`
+	TracingEndpoint string `config:"tracing.endpoint"`
+
+	// Откуда загружена конфигурация: профиль и прочитанные файлы
+	Profile     string
+	ConfigFiles []string
+
+	// Источник значения каждой настройки, см. Entries
+	origins map[string]string
+	// Ключи API, обязательные для подкоманды, см. LoadConfig
+	required []string
+}
+
+// setting связывает ключ файла конфигурации с переменной окружения и значением по умолчанию.
+// Переменные окружения называются так же, как до появления файла конфигурации.
+type setting struct {
+	key string
+	env string
+	def any
+}
+
+// defaultDomains — технологические издания, которыми по умолчанию ограничен поиск
+var defaultDomains = []string{
+	"techcrunch.com",
+	"theverge.com",
+	"wired.com",
+	"arstechnica.com",
+	"engadget.com",
+	"zdnet.com",
+	"venturebeat.com",
+	"thenextweb.com",
+}
+
+// defaultKeywords — технологические темы, по которым статьи оцениваются по умолчанию
+var defaultKeywords = []string{
+	"technology", "tech", "software", "AI", "artificial intelligence",
+	"cybersecurity", "digital", "innovation", "startup", "algorithm", "cloud", "data",
+	"security", "privacy", "blockchain", "machine learning",
+}
+
+var settings = []setting{
+	{"telegram.token", "TELEGRAM_BOT_TOKEN", nil},
+	{"telegram.moderation.enabled", "MODERATION_ENABLED", false},
+	{"telegram.moderation.editors_chat_id", "EDITORS_CHAT_ID", 0},
+	{"telegram.moderation.timeout", "MODERATION_TIMEOUT", "2h"}, // Через 2 часа без решения статья публикуется автоматически
+	{"telegram.moderation.candidates", "MODERATION_CANDIDATES", 3},
+	{"telegram.dry_run.enabled", "DRY_RUN", false},
+	{"telegram.dry_run.output", "DRY_RUN_OUTPUT", nil},
+	{"telegram.dry_run.allowlist", "DRY_RUN_ALLOWLIST", nil}, // идентификаторы чатов через запятую
+
+	{"sources.newsapi.api_key", "NEWS_API_KEY", nil},
+	{"sources.newsapi.category", "NEWS_CATEGORY", "technology"},
+	{"sources.newsapi.language", "NEWS_LANGUAGE", "en"},
+	{"sources.newsapi.endpoint", "NEWS_ENDPOINT", endpointEverything},
+	{"sources.newsapi.query", "NEWS_QUERY", "technology"},
+	{"sources.newsapi.domains", "NEWS_DOMAINS", defaultDomains}, // домены через запятую
+	{"sources.newsapi.exclude_domains", "NEWS_EXCLUDE_DOMAINS", nil},
+	{"sources.newsapi.sort_by", "NEWS_SORT_BY", "publishedAt"},
+	{"sources.newsapi.country", "NEWS_COUNTRY", nil},
+	{"sources.newsapi.page_size", "NEWS_PAGE_SIZE", 10},
+	{"sources.newsapi.pages", "NEWS_PAGES", 1},
+	{"sources.newsapi.from", "NEWS_FROM", nil}, // например 48h — статьи не старше двух суток
+	{"sources.newsapi.to", "NEWS_TO", nil},
+	{"sources.newsapi.daily_quota", "NEWS_API_DAILY_QUOTA", 100}, // лимит бесплатного тарифа NewsAPI
+	{"sources.newsapi.quota_path", "NEWS_API_QUOTA_PATH", nil},   // по умолчанию — quota/newsapi.org.json рядом с базой
+	{"sources.http.timeout", "SOURCES_HTTP_TIMEOUT", "10s"},
+	{"sources.http.max_retries", "SOURCES_HTTP_MAX_RETRIES", 3},
+	{"sources.http.retry_base_delay", "SOURCES_HTTP_RETRY_BASE_DELAY", "1s"},
+	{"sources.http.retry_max_delay", "SOURCES_HTTP_RETRY_MAX_DELAY", "30s"},
+	{"sources.http.min_interval", "SOURCES_HTTP_MIN_INTERVAL", "1s"},
+	{"sources.http.breaker_threshold", "SOURCES_HTTP_BREAKER_THRESHOLD", 5}, // неудачных попыток подряд; 0 отключает автомат
+	{"sources.http.breaker_cooldown", "SOURCES_HTTP_BREAKER_COOLDOWN", "10m"},
+	{"sources.cache.dir", "SOURCES_CACHE_DIR", nil},
+	{"sources.cache.ttl", "SOURCES_CACHE_TTL", "10m"},
+	{"sources.clustering.threshold", "SOURCES_CLUSTERING_THRESHOLD", 0.2},
+
+	{"scoring.weights.freshness", "SCORING_WEIGHT_FRESHNESS", 100},
+	{"scoring.weights.completeness", "SCORING_WEIGHT_COMPLETENESS", 80},
+	{"scoring.weights.source", "SCORING_WEIGHT_SOURCE", 60},
+	{"scoring.weights.topics", "SCORING_WEIGHT_TOPICS", 60},
+	{"scoring.weights.coverage", "SCORING_WEIGHT_COVERAGE", 80},
+	{"scoring.weights.feedback", "SCORING_WEIGHT_FEEDBACK", 60},
+	{"scoring.weights.blocklist", "SCORING_WEIGHT_BLOCKLIST", 200},
+	{"scoring.freshness_half_life", "SCORING_FRESHNESS_HALF_LIFE", "24h"},
+	{"scoring.sources", "SCORING_SOURCES", nil}, // techcrunch.com=1,example.com=-0.5
+	{"scoring.keywords", "SCORING_KEYWORDS", defaultKeywords},
+	{"scoring.blocklist", "SCORING_BLOCKLIST", nil},
+	{"scoring.llm.enabled", "SCORING_LLM_ENABLED", false},
+	{"scoring.llm.shortlist", "SCORING_LLM_SHORTLIST", 5},
+	{"scoring.llm.weight", "SCORING_LLM_WEIGHT", 150},
+
+	{"filters.allow_domains", "FILTERS_ALLOW_DOMAINS", nil},
+	{"filters.block_domains", "FILTERS_BLOCK_DOMAINS", nil},
+	{"filters.title_patterns", "FILTERS_TITLE_PATTERNS", []string{
+		`(?i)\bbest\b.*\bdeals\b`, `(?i)\bdeals? of the (day|week)\b`, `(?i)black friday`,
+		`(?i)\bsponsored\b`, `(?i)\bcoupons?\b`, `(?i)\bpromo codes?\b`,
+	}}, // подборки скидок, а не новости о сделках компаний; в переменной — по одному на строку
+	{"filters.min_content_length", "FILTERS_MIN_CONTENT_LENGTH", 50},
+	{"filters.max_age", "FILTERS_MAX_AGE", "0s"}, // 0 — без ограничения
+	{"filters.detect_language", "FILTERS_DETECT_LANGUAGE", true},
+
+	{"summarizer.api_key", "OPENAI_API_KEY", nil},
+	{"summarizer.quiz.enabled", "QUIZ_ENABLED", false},
+	{"summarizer.quiz.questions", "QUIZ_QUESTIONS", 3},
+
+	{"schedules.news", "SCHEDULE_TIME", "0 9 * * *"},      // По умолчанию в 9:00 каждый день
+	{"schedules.review", "REVIEW_SCHEDULE", "0 19 * * *"}, // Повторение терминов в 19:00
+
+	{"server.addr", "HTTP_ADDR", ""}, // Выключен: эндпоинты без авторизации
+
+	{"log.level", "LOG_LEVEL", "info"},
+	{"log.format", "LOG_FORMAT", "text"},
+
+	{"tracing.exporter", "TRACING_EXPORTER", "none"},
+	{"tracing.endpoint", "TRACING_ENDPOINT", nil},
+
+	{"storage.backend", "STORAGE_BACKEND", "sqlite"},
+	{"storage.sqlite_path", "DB_PATH", "/app/data/users.db"},
+	{"storage.postgres_dsn", "POSTGRES_DSN", nil},
+	{"storage.fallback", "STORAGE_FALLBACK", "fail"},
+	{"storage.memory_snapshot_path", "MEMORY_SNAPSHOT_PATH", nil},
+}
+
+// secrets — настройки с секретами: они скрываются при выводе конфигурации и могут
+// читаться из файла, путь к которому задан переменной с суффиксом _FILE
+var secrets = map[string]bool{
+	"telegram.token":          true,
+	"sources.newsapi.api_key": true,
+	"summarizer.api_key":      true,
+	"storage.postgres_dsn":    true,
+}
+
+// Переменные окружения, управляющие загрузкой самой конфигурации
+const (
+	// ConfigFileEnv — путь к файлу конфигурации; без него файл ищется в configDirs
+	ConfigFileEnv = "CONFIG_FILE"
+	// ProfileEnv — профиль окружения (dev, prod и т. п.), значения которого
+	// берутся из файла config.<профиль>.<расширение> поверх основного
+	ProfileEnv = "CONFIG_PROFILE"
+)
+
+// Ключи API внешних сервисов. Подкоманда передаёт в LoadConfig только те,
+// которыми пользуется: migrate или config print работают и без них.
+const (
+	TelegramToken = "telegram.token"
+	NewsAPIKey    = "sources.newsapi.api_key"
+	OpenAIAPIKey  = "summarizer.api_key"
+)
+
+// configDirs — каталоги, в которых ищется config.yaml, config.yml или config.toml
+var configDirs = []string{".", "/app/config"}
+
+var configExts = []string{"yaml", "yml", "toml"}
+
+// LoadConfig загружает конфигурацию. Источники по убыванию приоритета: файлы секретов
+// из переменных *_FILE, переменные окружения, файл .env, файл профиля, основной файл
+// конфигурации и значения по умолчанию. Все ошибки проверки возвращаются вместе.
+// required — ключи API (TelegramToken, NewsAPIKey, OpenAIAPIKey), без которых вызывающая
+// подкоманда не работает; остальные ключи могут быть не заданы.
+func LoadConfig(required ...string) (*Config, error) {
+	loadDotEnv()
+
+	v := viper.New()
+	v.AllowEmptyEnv(true) // пустая переменная, например REVIEW_SCHEDULE=, отключает функцию
+	for _, s := range settings {
+		if s.def != nil {
+			v.SetDefault(s.key, s.def)
+		}
+		if err := v.BindEnv(s.key, s.env); err != nil {
+			return nil, err
+		}
+	}
+
+	profile := os.Getenv(ProfileEnv)
+	files, err := readConfigFiles(v, os.Getenv(ConfigFileEnv), profile)
+	if err != nil {
+		return nil, err
+	}
+
+	secretErrs := readSecretFiles(v)
+
+	r := &reader{v: v}
+	cfg := &Config{
+		TelegramBotToken: r.string("telegram.token"),
+		NewsAPIKey:       r.string("sources.newsapi.api_key"),
+		OpenAIAPIKey:     r.string("summarizer.api_key"),
+		NewsCategory:     r.string("sources.newsapi.category"),
+		NewsLanguage:     r.string("sources.newsapi.language"),
+		ScheduleTime:     r.string("schedules.news"),
+		ReviewSchedule:   r.string("schedules.review"),
+		DBPath:           r.string("storage.sqlite_path"),
+
+		StorageBackend:     r.string("storage.backend"),
+		PostgresDSN:        r.string("storage.postgres_dsn"),
+		StorageFallback:    r.string("storage.fallback"),
+		MemorySnapshotPath: r.string("storage.memory_snapshot_path"),
+
+		QuizEnabled:   r.bool("summarizer.quiz.enabled"),
+		QuizQuestions: r.int("summarizer.quiz.questions"),
+
+		ModerationEnabled:    r.bool("telegram.moderation.enabled"),
+		EditorsChatID:        r.int64("telegram.moderation.editors_chat_id"),
+		ModerationTimeout:    r.duration("telegram.moderation.timeout"),
+		ModerationCandidates: r.int("telegram.moderation.candidates"),
+
+		DryRun:          r.bool("telegram.dry_run.enabled"),
+		DryRunOutput:    r.string("telegram.dry_run.output"),
+		DryRunAllowlist: r.int64s("telegram.dry_run.allowlist"),
+
+		SourceTimeout:          r.duration("sources.http.timeout"),
+		SourceMaxRetries:       r.int("sources.http.max_retries"),
+		SourceRetryBaseDelay:   r.duration("sources.http.retry_base_delay"),
+		SourceRetryMaxDelay:    r.duration("sources.http.retry_max_delay"),
+		SourceMinInterval:      r.duration("sources.http.min_interval"),
+		SourceBreakerThreshold: r.int("sources.http.breaker_threshold"),
+		SourceBreakerCooldown:  r.duration("sources.http.breaker_cooldown"),
+		SourceCacheDir:         r.string("sources.cache.dir"),
+		SourceCacheTTL:         r.duration("sources.cache.ttl"),
+		NewsAPIDailyQuota:      r.int("sources.newsapi.daily_quota"),
+		NewsAPIQuotaPath:       r.string("sources.newsapi.quota_path"),
+
+		NewsEndpoint:       r.string("sources.newsapi.endpoint"),
+		NewsQuery:          r.string("sources.newsapi.query"),
+		NewsDomains:        r.strings("sources.newsapi.domains"),
+		NewsExcludeDomains: r.strings("sources.newsapi.exclude_domains"),
+		NewsSortBy:         r.string("sources.newsapi.sort_by"),
+		NewsCountry:        strings.ToLower(r.string("sources.newsapi.country")),
+		NewsPageSize:       r.int("sources.newsapi.page_size"),
+		NewsPages:          r.int("sources.newsapi.pages"),
+		NewsFrom:           r.duration("sources.newsapi.from"),
+		NewsTo:             r.duration("sources.newsapi.to"),
+
+		NewsClusterThreshold: r.float64("sources.clustering.threshold"),
+
+		ScoringFreshnessWeight:    r.float64("scoring.weights.freshness"),
+		ScoringCompletenessWeight: r.float64("scoring.weights.completeness"),
+		ScoringSourceWeight:       r.float64("scoring.weights.source"),
+		ScoringTopicsWeight:       r.float64("scoring.weights.topics"),
+		ScoringCoverageWeight:     r.float64("scoring.weights.coverage"),
+		ScoringFeedbackWeight:     r.float64("scoring.weights.feedback"),
+		ScoringBlocklistWeight:    r.float64("scoring.weights.blocklist"),
+		ScoringFreshnessHalfLife:  r.duration("scoring.freshness_half_life"),
+		ScoringSources:            r.weights("scoring.sources"),
+		ScoringKeywords:           r.strings("scoring.keywords"),
+		ScoringBlocklist:          r.strings("scoring.blocklist"),
+
+		ScoringLLMEnabled:   r.bool("scoring.llm.enabled"),
+		ScoringLLMShortlist: r.int("scoring.llm.shortlist"),
+		ScoringLLMWeight:    r.float64("scoring.llm.weight"),
+
+		FilterAllowDomains:     r.strings("filters.allow_domains"),
+		FilterBlockDomains:     r.strings("filters.block_domains"),
+		FilterTitlePatterns:    r.lines("filters.title_patterns"),
+		FilterMinContentLength: r.int("filters.min_content_length"),
+		FilterMaxAge:           r.duration("filters.max_age"),
+		FilterDetectLanguage:   r.bool("filters.detect_language"),
+
+		HTTPAddr: r.string("server.addr"),
+
+		LogLevel:  r.string("log.level"),
+		LogFormat: r.string("log.format"),
+
+		TracingExporter: r.string("tracing.exporter"),
+		TracingEndpoint: r.string("tracing.endpoint"),
+
+		Profile:     profile,
+		ConfigFiles: files,
+		origins:     origins(files),
+		required:    required,
+	}
+
+	// Запрос по умолчанию рассчитан на everything: в top-headlines он оставил бы от категории
+	// только статьи со словом technology, поэтому туда передаётся лишь явно заданный запрос
+	if cfg.NewsEndpoint == endpointTopHeadlines && cfg.origins["sources.newsapi.query"] == OriginDefault {
+		cfg.NewsQuery = ""
+	}
+	// Расход квоты по умолчанию хранится в каталоге данных рядом с базой SQLite;
+	// явно заданная пустая строка оставляет его в памяти
+	if cfg.origins["sources.newsapi.quota_path"] == OriginDefault {
+		cfg.NewsAPIQuotaPath = filepath.Join(filepath.Dir(cfg.DBPath), "quota", "newsapi.org.json")
+	}
+
+	errs := append(secretErrs, r.errs...)
+	errs = append(errs, unknownKeys(v)...)
+	errs = append(errs, cfg.validate()...)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+
+	return cfg, nil
+}
+
+// readConfigFiles читает основной файл конфигурации и файл профиля, если они есть,
+// и возвращает их пути. Без файлов конфигурация берётся из окружения.
+func readConfigFiles(v *viper.Viper, path, profile string) ([]string, error) {
+	if path == "" {
+		path = findConfigFile(configDirs, "config")
+	} else if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	var files []string
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+		}
+		files = append(files, path)
+	}
+
+	if profile == "" {
+		return files, nil
+	}
+
+	dirs := configDirs
+	if path != "" {
+		dirs = []string{filepath.Dir(path)}
+	}
+	overlay := findConfigFile(dirs, "config."+profile)
+	if overlay == "" {
+		return nil, fmt.Errorf("config file for profile %q not found: expected config.%s.yaml or config.%s.toml", profile, profile, profile)
+	}
+
+	v.SetConfigFile(overlay)
+	if err := v.MergeInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", overlay, err)
+	}
+
+	return append(files, overlay), nil
+}
+
+// findConfigFile ищет файл name с одним из поддерживаемых расширений
+func findConfigFile(dirs []string, name string) string {
+	for _, dir := range dirs {
+		for _, ext := range configExts {
+			path := filepath.Join(dir, name+"."+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
+
+// unknownKeys сообщает о ключах файла, которых нет среди настроек, — обычно это опечатки
+func unknownKeys(v *viper.Viper) []error {
+	known := make(map[string]bool, len(settings))
+	for _, s := range settings {
+		known[s.key] = true
+	}
+
+	var errs []error
+	for _, key := range v.AllKeys() {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("%s: unknown setting", key))
+		}
+	}
+
+	return errs
+}
+
+// reader читает значения настроек, запоминая ошибки преобразования типов
+type reader struct {
+	v    *viper.Viper
+	errs []error
+}
+
+func (r *reader) string(key string) string {
+	return strings.TrimSpace(r.v.GetString(key))
+}
+
+func (r *reader) bool(key string) bool {
+	value, err := cast.ToBoolE(r.v.Get(key))
+	r.check(key, "boolean", err)
+	return value
+}
+
+func (r *reader) int(key string) int {
+	value, err := cast.ToIntE(r.v.Get(key))
+	r.check(key, "integer", err)
+	return value
+}
+
+func (r *reader) float64(key string) float64 {
+	value, err := cast.ToFloat64E(r.v.Get(key))
+	r.check(key, "number", err)
+	return value
+}
+
+func (r *reader) int64(key string) int64 {
+	value, err := cast.ToInt64E(r.v.Get(key))
+	r.check(key, "integer", err)
+	return value
+}
+
+// int64s читает список чисел: из файла — списком, из переменной окружения — через запятую
+func (r *reader) int64s(key string) []int64 {
+	var values []int64
+	for _, item := range r.list(key, "integers", ",") {
+		value, err := cast.ToInt64E(item)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("%s: %q is not a valid integer", name(key), fmt.Sprint(item)))
+			continue
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// strings читает список строк так же, как int64s
+func (r *reader) strings(key string) []string {
+	return r.stringList(key, ",")
+}
+
+// lines читает список, элементы которого могут содержать запятые, например регулярные
+// выражения: в переменной окружения они разделяются переводом строки
+func (r *reader) lines(key string) []string {
+	return r.stringList(key, "\n")
+}
+
+func (r *reader) stringList(key, sep string) []string {
+	var values []string
+	for _, item := range r.list(key, "strings", sep) {
+		if value := strings.TrimSpace(cast.ToString(item)); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// weights читает список пар «имя=число», например techcrunch.com=1
+func (r *reader) weights(key string) map[string]float64 {
+	var values map[string]float64
+	for _, item := range r.strings(key) {
+		label, value, ok := strings.Cut(item, "=")
+		weight, err := cast.ToFloat64E(strings.TrimSpace(value))
+		if !ok || err != nil || strings.TrimSpace(label) == "" {
+			r.errs = append(r.errs, fmt.Errorf("%s: %q is not a valid name=number pair", name(key), item))
+			continue
+		}
+		if values == nil {
+			values = make(map[string]float64)
+		}
+		values[strings.TrimSpace(label)] = weight
+	}
+
+	return values
+}
+
+// list возвращает элементы списка: из файла — списком, из переменной окружения — через sep
+// или JSON-массивом, например ["a,b", "c"]. Строка, которая не разбирается как JSON-массив,
+// делится по sep: так регулярное выражение [Ss]ponsored остаётся одним элементом.
+func (r *reader) list(key, kind, sep string) []any {
+	var items []any
+	switch value := r.v.Get(key).(type) {
+	case nil:
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(value), "[") && json.Unmarshal([]byte(value), &items) == nil {
+			return items
+		}
+		items = nil
+		for _, item := range strings.Split(value, sep) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	case []string:
+		// значение по умолчанию
+		for _, item := range value {
+			items = append(items, item)
+		}
+	default:
+		list, err := cast.ToSliceE(value)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("%s: %v is not a list of %s", name(key), value, kind))
+			return nil
+		}
+		items = list
+	}
+
+	return items
+}
+
+func (r *reader) duration(key string) time.Duration {
+	value, err := cast.ToDurationE(r.v.Get(key))
+	r.check(key, "duration such as 90m or 2h", err)
+	return value
+}
+
+// check пропускает пустые значения: обязательность проверяется в validate
+func (r *reader) check(key, kind string, err error) {
+	if err != nil && r.v.GetString(key) != "" {
+		r.errs = append(r.errs, fmt.Errorf("%s: %q is not a valid %s", name(key), r.v.GetString(key), kind))
+	}
+}
+
+// name возвращает ключ настройки вместе с переменной окружения для сообщений об ошибках
+func name(key string) string {
+	for _, s := range settings {
+		if s.key == key {
+			return fmt.Sprintf("%s (%s)", s.key, s.env)
+		}
+	}
+
+	return key
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
+)
+
+// Источники значений настроек
+const (
+	OriginDefault = "default"
+	OriginFile    = "file"
+	OriginEnv     = "env"
+)
+
+// dotenv запоминает переменные, которые задал файл .env, а не окружение процесса
+var dotenv = struct {
+	sync.Mutex
+	keys map[string]bool
+}{keys: make(map[string]bool)}
+
+// loadDotEnv переносит значения из .env в переменные окружения, не перекрывая уже заданные
+func loadDotEnv() {
+	env, err := gotenv.Read(".env")
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("Error reading .env file", "error", err)
+		}
+		return
+	}
+
+	dotenv.Lock()
+	defer dotenv.Unlock()
+
+	for key, value := range env {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		os.Setenv(key, value)
+		dotenv.keys[key] = true
+	}
+}
+
+// readSecretFiles читает секреты из файлов, пути к которым заданы переменными вида
+// TELEGRAM_BOT_TOKEN_FILE — так передаются секреты Docker и Kubernetes
+func readSecretFiles(v *viper.Viper) []error {
+	var errs []error
+
+	for _, s := range settings {
+		path := secretFile(s)
+		if path == "" {
+			continue
+		}
+		if os.Getenv(s.env) != "" {
+			errs = append(errs, fmt.Errorf("%s: set either %s or %s_FILE, not both", s.key, s.env, s.env))
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s_FILE): error reading secret: %w", s.key, s.env, err))
+			continue
+		}
+		v.Set(s.key, strings.TrimSpace(string(data)))
+	}
+
+	return errs
+}
+
+// secretFile возвращает путь к файлу секрета настройки или пустую строку
+func secretFile(s setting) string {
+	if !secrets[s.key] {
+		return ""
+	}
+	return os.Getenv(s.env + "_FILE")
+}
+
+// origins определяет, откуда взято значение каждой настройки. Порядок проверки
+// повторяет приоритет источников в LoadConfig.
+func origins(files []string) map[string]string {
+	// Файл профиля читается после основного, поэтому его значения побеждают
+	inFile := make(map[string]string)
+	for _, path := range files {
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			continue
+		}
+		for _, key := range v.AllKeys() {
+			inFile[key] = path
+		}
+	}
+
+	dotenv.Lock()
+	defer dotenv.Unlock()
+
+	result := make(map[string]string, len(settings))
+	for _, s := range settings {
+		_, inEnv := os.LookupEnv(s.env)
+		switch {
+		case secretFile(s) != "":
+			result[s.key] = fmt.Sprintf("%s %s_FILE (%s)", OriginEnv, s.env, secretFile(s))
+		case inEnv && dotenv.keys[s.env]:
+			result[s.key] = fmt.Sprintf("%s %s (.env)", OriginEnv, s.env)
+		case inEnv:
+			result[s.key] = fmt.Sprintf("%s %s", OriginEnv, s.env)
+		case inFile[s.key] != "":
+			result[s.key] = fmt.Sprintf("%s %s", OriginFile, inFile[s.key])
+		default:
+			result[s.key] = OriginDefault
+		}
+	}
+
+	return result
+}
+
+// Entry — действующее значение настройки для вывода командой config print
+type Entry struct {
+	Key    string
+	Env    string
+	Value  string
+	Origin string
+}
+
+// Entries возвращает все настройки в порядке объявления; секреты скрыты
+func (c *Config) Entries() []Entry {
+	fields := make(map[string]reflect.Value)
+	cv := reflect.ValueOf(c).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		if key := cv.Type().Field(i).Tag.Get("config"); key != "" {
+			fields[key] = cv.Field(i)
+		}
+	}
+
+	entries := make([]Entry, 0, len(settings))
+	for _, s := range settings {
+		value := fmt.Sprint(fields[s.key].Interface())
+		if secrets[s.key] {
+			value = redact(s.key, value)
+		}
+
+		origin := c.origins[s.key]
+		if origin == "" {
+			origin = OriginDefault
+		}
+
+		entries = append(entries, Entry{Key: s.key, Env: s.env, Value: value, Origin: origin})
+	}
+
+	return entries
+}
+
+var dsnPassword = regexp.MustCompile(`(password=)\S+`)
+
+// redact скрывает секрет. В строке подключения к базе скрывается только пароль,
+// чтобы было видно, к какому серверу подключается бот.
+func redact(key, value string) string {
+	if value == "" {
+		return ""
+	}
+	if key != "storage.postgres_dsn" {
+		return "********"
+	}
+
+	if strings.Contains(value, "://") {
+		u, err := url.Parse(value)
+		if err != nil {
+			return "********"
+		}
+		return u.Redacted()
+	}
+
+	return dsnPassword.ReplaceAllString(value, "${1}xxxxx")
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/tracing"
+	"github.com/robfig/cron/v3"
+)
+
+// Методы NewsAPI, из которых берутся статьи
+const (
+	endpointEverything   = "everything"
+	endpointTopHeadlines = "top-headlines"
+)
+
+// Значения, которые принимает NewsAPI
+var (
+	newsLanguages  = []string{"ar", "de", "en", "es", "fr", "he", "it", "nl", "no", "pt", "ru", "sv", "ud", "zh"}
+	newsCategories = []string{"business", "entertainment", "general", "health", "science", "sports", "technology"}
+	newsSortOrders = []string{"relevancy", "popularity", "publishedAt"}
+)
+
+var countryCode = regexp.MustCompile(`^[a-z]{2}$`)
+
+// validate проверяет значения настроек и возвращает все найденные ошибки
+func (c *Config) validate() []error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", name(key), fmt.Sprintf(format, args...)))
+	}
+
+	keys := map[string]string{
+		TelegramToken: c.TelegramBotToken,
+		NewsAPIKey:    c.NewsAPIKey,
+		OpenAIAPIKey:  c.OpenAIAPIKey,
+	}
+	for _, s := range settings {
+		if value, ok := keys[s.key]; ok && value == "" && slices.Contains(c.required, s.key) {
+			fail(s.key, "is required")
+		}
+	}
+
+	// Пустая категория — главные новости всех категорий
+	if c.NewsCategory != "" && !contains(newsCategories, c.NewsCategory) {
+		fail("sources.newsapi.category", "must be one of %s, got %q", strings.Join(newsCategories, ", "), c.NewsCategory)
+	}
+	if !contains(newsLanguages, c.NewsLanguage) {
+		fail("sources.newsapi.language", "must be one of %s, got %q", strings.Join(newsLanguages, ", "), c.NewsLanguage)
+	}
+	switch c.NewsEndpoint {
+	case endpointEverything:
+		// everything требует хотя бы поисковый запрос или список доменов
+		if c.NewsQuery == "" && len(c.NewsDomains) == 0 {
+			fail("sources.newsapi.query", "must be set when sources.newsapi.domains is empty")
+		}
+	case endpointTopHeadlines:
+	default:
+		fail("sources.newsapi.endpoint", "must be %s or %s, got %q", endpointEverything, endpointTopHeadlines, c.NewsEndpoint)
+	}
+	if !contains(newsSortOrders, c.NewsSortBy) {
+		fail("sources.newsapi.sort_by", "must be one of %s, got %q", strings.Join(newsSortOrders, ", "), c.NewsSortBy)
+	}
+	if c.NewsCountry != "" && !countryCode.MatchString(c.NewsCountry) {
+		fail("sources.newsapi.country", "must be a two-letter country code such as us, got %q", c.NewsCountry)
+	}
+	if c.NewsPageSize < 1 || c.NewsPageSize > 100 {
+		fail("sources.newsapi.page_size", "must be between 1 and 100, got %d", c.NewsPageSize)
+	}
+	if c.NewsPages < 1 {
+		fail("sources.newsapi.pages", "must be at least 1, got %d", c.NewsPages)
+	}
+	if c.NewsFrom < 0 {
+		fail("sources.newsapi.from", "must not be negative, got %s", c.NewsFrom)
+	}
+	if c.NewsTo < 0 {
+		fail("sources.newsapi.to", "must not be negative, got %s", c.NewsTo)
+	}
+	if c.NewsFrom > 0 && c.NewsTo >= c.NewsFrom {
+		fail("sources.newsapi.to", "must be less than sources.newsapi.from, got %s and %s", c.NewsTo, c.NewsFrom)
+	}
+
+	if c.SourceTimeout <= 0 {
+		fail("sources.http.timeout", "must be positive, got %s", c.SourceTimeout)
+	}
+	if c.SourceMaxRetries < 0 {
+		fail("sources.http.max_retries", "must not be negative, got %d", c.SourceMaxRetries)
+	}
+	if c.SourceRetryBaseDelay <= 0 {
+		fail("sources.http.retry_base_delay", "must be positive, got %s", c.SourceRetryBaseDelay)
+	}
+	if c.SourceRetryMaxDelay < c.SourceRetryBaseDelay {
+		fail("sources.http.retry_max_delay", "must not be less than sources.http.retry_base_delay, got %s", c.SourceRetryMaxDelay)
+	}
+	if c.SourceMinInterval < 0 {
+		fail("sources.http.min_interval", "must not be negative, got %s", c.SourceMinInterval)
+	}
+	if c.SourceBreakerThreshold < 0 {
+		fail("sources.http.breaker_threshold", "must not be negative, got %d", c.SourceBreakerThreshold)
+	}
+	if c.SourceBreakerThreshold > 0 && c.SourceBreakerCooldown <= 0 {
+		fail("sources.http.breaker_cooldown", "must be positive, got %s", c.SourceBreakerCooldown)
+	}
+
+	if c.SourceCacheTTL < 0 {
+		fail("sources.cache.ttl", "must not be negative, got %s", c.SourceCacheTTL)
+	}
+	if c.NewsClusterThreshold < 0 || c.NewsClusterThreshold > 1 {
+		fail("sources.clustering.threshold", "must be between 0 and 1, got %g", c.NewsClusterThreshold)
+	}
+	weights := map[string]float64{
+		"scoring.weights.freshness":    c.ScoringFreshnessWeight,
+		"scoring.weights.completeness": c.ScoringCompletenessWeight,
+		"scoring.weights.source":       c.ScoringSourceWeight,
+		"scoring.weights.topics":       c.ScoringTopicsWeight,
+		"scoring.weights.coverage":     c.ScoringCoverageWeight,
+		"scoring.weights.feedback":     c.ScoringFeedbackWeight,
+		"scoring.weights.blocklist":    c.ScoringBlocklistWeight,
+	}
+	for _, s := range settings {
+		if weight, ok := weights[s.key]; ok && weight < 0 {
+			fail(s.key, "must not be negative, got %g", weight)
+		}
+	}
+	if c.ScoringFreshnessWeight > 0 && c.ScoringFreshnessHalfLife <= 0 {
+		fail("scoring.freshness_half_life", "must be positive, got %s", c.ScoringFreshnessHalfLife)
+	}
+	for _, source := range slices.Sorted(maps.Keys(c.ScoringSources)) {
+		if reputation := c.ScoringSources[source]; reputation < -1 || reputation > 1 {
+			fail("scoring.sources", "reputation of %s must be between -1 and 1, got %g", source, reputation)
+		}
+	}
+	if c.ScoringLLMEnabled {
+		if c.ScoringLLMShortlist < 2 || c.ScoringLLMShortlist > 20 {
+			fail("scoring.llm.shortlist", "must be between 2 and 20, got %d", c.ScoringLLMShortlist)
+		}
+		if c.ScoringLLMWeight <= 0 {
+			fail("scoring.llm.weight", "must be positive, got %g", c.ScoringLLMWeight)
+		}
+	}
+	for _, pattern := range c.FilterTitlePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			fail("filters.title_patterns", "invalid regular expression %q: %v", pattern, err)
+		}
+	}
+	if c.FilterMinContentLength < 0 {
+		fail("filters.min_content_length", "must not be negative, got %d", c.FilterMinContentLength)
+	}
+	if c.FilterMaxAge < 0 {
+		fail("filters.max_age", "must not be negative, got %s", c.FilterMaxAge)
+	}
+	if c.NewsAPIDailyQuota < 0 {
+		fail("sources.newsapi.daily_quota", "must not be negative, got %d", c.NewsAPIDailyQuota)
+	}
+
+	if _, err := cron.ParseStandard(c.ScheduleTime); err != nil {
+		fail("schedules.news", "invalid cron expression %q: %v", c.ScheduleTime, err)
+	}
+	if c.ReviewSchedule != "" {
+		if _, err := cron.ParseStandard(c.ReviewSchedule); err != nil {
+			fail("schedules.review", "invalid cron expression %q: %v", c.ReviewSchedule, err)
+		}
+	}
+
+	if c.HTTPAddr != "" {
+		if _, port, err := net.SplitHostPort(c.HTTPAddr); err != nil || port == "" {
+			fail("server.addr", "must be host:port or :port, got %q", c.HTTPAddr)
+		}
+	}
+
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		fail("log.level", "must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+	if c.LogFormat != logging.FormatText && c.LogFormat != logging.FormatJSON {
+		fail("log.format", "must be text or json, got %q", c.LogFormat)
+	}
+
+	switch c.TracingExporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		fail("tracing.exporter", "must be none, stdout or otlp, got %q", c.TracingExporter)
+	}
+	if c.TracingEndpoint != "" {
+		if u, err := url.Parse(c.TracingEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("tracing.endpoint", "must be an http or https URL, got %q", c.TracingEndpoint)
+		}
+	}
+
+	switch c.StorageBackend {
+	case "sqlite":
+		if c.DBPath == "" {
+			fail("storage.sqlite_path", "must be set when storage.backend is sqlite")
+		}
+	case "memory":
+	case "postgres":
+		if c.PostgresDSN == "" {
+			fail("storage.postgres_dsn", "must be set when storage.backend is postgres")
+		}
+	default:
+		fail("storage.backend", "must be sqlite, postgres or memory, got %q", c.StorageBackend)
+	}
+	// DSN вида "host=... dbname=..." тоже допустим, поэтому как URL проверяется только строка со схемой
+	if strings.Contains(c.PostgresDSN, "://") {
+		if u, err := url.Parse(c.PostgresDSN); err != nil {
+			fail("storage.postgres_dsn", "invalid URL: %v", err)
+		} else if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+			fail("storage.postgres_dsn", "URL scheme must be postgres or postgresql, got %q", u.Scheme)
+		}
+	}
+
+	switch c.StorageFallback {
+	case "fail", "memory":
+	default:
+		fail("storage.fallback", "must be fail or memory, got %q", c.StorageFallback)
+	}
+
+	if c.QuizEnabled && (c.QuizQuestions < 1 || c.QuizQuestions > 5) {
+		fail("summarizer.quiz.questions", "must be between 1 and 5, got %d", c.QuizQuestions)
+	}
+
+	if c.ModerationEnabled {
+		if c.EditorsChatID == 0 {
+			fail("telegram.moderation.editors_chat_id", "must be set when moderation is enabled")
+		}
+		if c.ModerationTimeout <= 0 {
+			fail("telegram.moderation.timeout", "must be positive, got %s", c.ModerationTimeout)
+		}
+		if c.ModerationCandidates < 1 {
+			fail("telegram.moderation.candidates", "must be at least 1, got %d", c.ModerationCandidates)
+		}
+	}
+
+	return errs
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// reloadDelay — пауза после изменения файла: редакторы часто записывают файл в несколько приёмов
+const reloadDelay = 500 * time.Millisecond
+
+// Watch следит за файлами конфигурации cfg и после их изменения загружает конфигурацию заново.
+// Конфигурация, прошедшая проверку и отличающаяся от предыдущей, передаётся в apply;
+// ошибка загрузки передаётся в reject, и предыдущая конфигурация остаётся в силе.
+// Переменные окружения и .env при перезагрузке не меняются. Возвращает false, если
+// конфигурация загружена без файлов и следить не за чем.
+func Watch(cfg *Config, apply func(*Config), reject func(error)) bool {
+	if len(cfg.ConfigFiles) == 0 {
+		return false
+	}
+
+	var mu sync.Mutex
+	current := cfg
+	var timer *time.Timer
+
+	reload := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		next, err := LoadConfig(cfg.required...)
+		if err != nil {
+			reject(err)
+			return
+		}
+		if reflect.DeepEqual(current, next) {
+			return
+		}
+
+		current = next
+		apply(next)
+	}
+
+	// Каждый файл отслеживается отдельным экземпляром viper: сам он файл только перечитывает,
+	// а итоговая конфигурация собирается заново из всех источников
+	for _, path := range cfg.ConfigFiles {
+		w := viper.New()
+		w.SetConfigFile(path)
+		w.OnConfigChange(func(fsnotify.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(reloadDelay, reload)
+		})
+		w.WatchConfig()
+	}
+
+	return true
+}
+
+// Changed возвращает ключи настроек, значения которых в next отличаются от prev
+func Changed(prev, next *Config) []string {
+	var keys []string
+
+	pv, nv := reflect.ValueOf(prev).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < pv.NumField(); i++ {
+		key := pv.Type().Field(i).Tag.Get("config")
+		if key == "" {
+			continue
+		}
+		if !reflect.DeepEqual(pv.Field(i).Interface(), nv.Field(i).Interface()) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
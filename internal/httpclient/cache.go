@@ -0,0 +1,204 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStatusHeader добавляется к ответам Cache и показывает, откуда взят ответ
+const CacheStatusHeader = "X-Cache-Status"
+
+// Значения CacheStatusHeader
+const (
+	// CacheHit — запись моложе TTL, запрос не выполнялся
+	CacheHit = "hit"
+	// CacheRevalidated — на условный запрос пришёл 304, тело взято из кэша
+	CacheRevalidated = "revalidated"
+	// CacheMiss — ответ получен от источника
+	CacheMiss = "miss"
+)
+
+// Cache кэширует успешные ответы на GET-запросы. Запись моложе TTL отдаётся без запроса,
+// более старая перепроверяется условным запросом с If-None-Match и If-Modified-Since.
+// Записи хранятся в файлах каталога dir, а если он не задан — в памяти.
+type Cache struct {
+	next   Doer
+	dir    string
+	ttl    time.Duration
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	URL          string      `json:"url"`
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	StoredAt     time.Time   `json:"stored_at"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+}
+
+func NewCache(next Doer, dir string, ttl time.Duration, logger *slog.Logger) *Cache {
+	return &Cache{
+		next:    next,
+		dir:     dir,
+		ttl:     ttl,
+		logger:  logger.With("component", "httpcache"),
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+func (c *Cache) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return c.next.Do(req)
+	}
+
+	key := cacheKey(req)
+	entry := c.load(key)
+	if entry != nil && time.Since(entry.StoredAt) < c.ttl {
+		return entry.response(req, CacheHit), nil
+	}
+
+	if entry != nil && (entry.ETag != "" || entry.LastModified != "") {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := c.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		entry.StoredAt = time.Now()
+		c.store(key, entry)
+		return entry.response(req, CacheRevalidated), nil
+	}
+
+	resp.Header.Set(CacheStatusHeader, CacheMiss)
+	if resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.store(key, &cacheEntry{
+		URL:          req.URL.String(),
+		Status:       resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		StoredAt:     time.Now(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+
+	return resp, nil
+}
+
+// response собирает ответ из записи кэша
+func (e *cacheEntry) response(req *http.Request, status string) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(CacheStatusHeader, status)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func (c *Cache) load(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dir == "" {
+		if entry, ok := c.entries[key]; ok {
+			copied := *entry
+			return &copied
+		}
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			c.logger.Warn("Error reading cache entry", "error", err)
+		}
+		return nil
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		c.logger.Warn("Error decoding cache entry, ignoring it", "path", c.path(key), "error", err)
+		return nil
+	}
+
+	return &entry
+}
+
+// store сохраняет запись; ошибка записи на диск только попадает в журнал, ответ всё равно отдаётся
+func (c *Cache) store(key string, entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dir == "" {
+		c.entries[key] = entry
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = os.MkdirAll(c.dir, 0755)
+	}
+	if err == nil {
+		err = writeFile(c.path(key), data)
+	}
+	if err != nil {
+		c.logger.Warn("Error writing cache entry", "error", err)
+	}
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// cacheKey — ключ записи: хэш URL. Ключ API передаётся в заголовке и в ключ не входит.
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String()))
+	return hex.EncodeToString(sum[:16])
+}
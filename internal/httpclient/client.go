@@ -0,0 +1,326 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andrei/goBot/internal/metrics"
+	"golang.org/x/time/rate"
+)
+
+// ErrCircuitOpen возвращается без обращения к хосту, пока его автомат защиты разомкнут
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Doer выполняет HTTP-запрос. Его реализуют Client и Cache, поэтому слои можно совмещать.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Options — настройки повторов, ограничения частоты и автомата защиты
+type Options struct {
+	// Timeout ограничивает одну попытку запроса
+	Timeout time.Duration
+	// MaxRetries — сколько раз повторить запрос после первой неудачной попытки
+	MaxRetries int
+	// Паузы между повторами растут экспоненциально от BaseDelay до MaxDelay.
+	// Retry-After длиннее MaxDelay не ждём: ответ возвращается вызывающему.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MinInterval — минимальный интервал между запросами к одному хосту; 0 отключает ограничение
+	MinInterval time.Duration
+	// После BreakerThreshold неудачных попыток подряд запросы к хосту не выполняются
+	// в течение BreakerCooldown; 0 отключает автомат защиты
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// Client выполняет запросы к источникам новостей: повторяет их при сетевых ошибках,
+// 429 и 5xx с экспоненциальной паузой и случайным разбросом, учитывает Retry-After,
+// ограничивает частоту запросов к каждому хосту и перестаёт обращаться к хосту,
+// который раз за разом отвечает ошибкой.
+type Client struct {
+	http   *http.Client
+	opts   Options
+	logger *slog.Logger
+	mu     sync.Mutex
+	hosts  map[string]*host
+}
+
+// host — ограничитель частоты и состояние автомата защиты одного хоста
+type host struct {
+	limiter *rate.Limiter
+	budget  *Budget
+
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func New(opts Options, logger *slog.Logger) *Client {
+	return &Client{
+		http:   &http.Client{Timeout: opts.Timeout},
+		opts:   opts,
+		logger: logger.With("component", "httpclient"),
+		hosts:  make(map[string]*host),
+	}
+}
+
+// Do выполняет запрос с повторами. Тело запроса при повторе берётся из req.GetBody,
+// поэтому повторяются только запросы без тела или с GetBody.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	name := req.URL.Host
+	h := c.host(name)
+
+	for attempt := 0; ; attempt++ {
+		if err := c.allow(name, h); err != nil {
+			return nil, err
+		}
+		if err := h.limiter.Wait(ctx); err != nil {
+			c.release(h)
+			return nil, err
+		}
+
+		if err := c.spend(ctx, name, h); err != nil {
+			c.release(h)
+			return nil, err
+		}
+
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			c.release(h)
+			return nil, err
+		}
+
+		resp, err := c.http.Do(attemptReq)
+		if err != nil && ctx.Err() != nil {
+			// Отменённый запрос ничего не говорит о состоянии хоста и не повторяется
+			c.release(h)
+			return nil, err
+		}
+		retryable, reason := classify(resp, err)
+		// Пока автомат разомкнут, повторять бессмысленно: возвращаем последний ответ
+		open := c.record(name, h, retryable)
+		if !retryable || open || attempt >= c.opts.MaxRetries {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				if after > c.opts.MaxDelay {
+					// Источник просит подождать дольше, чем имеет смысл ждать внутри запуска
+					return resp, nil
+				}
+				delay = after
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		metrics.HTTPRetry(name, reason)
+		args := []any{"host", name, "reason", reason, "attempt", attempt + 1, "delay", delay}
+		if err != nil {
+			args = append(args, "error", err)
+		}
+		c.logger.WarnContext(ctx, "Request failed, retrying", args...)
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) host(name string) *host {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, ok := c.hosts[name]
+	if !ok {
+		limit := rate.Inf
+		if c.opts.MinInterval > 0 {
+			limit = rate.Every(c.opts.MinInterval)
+		}
+		h = &host{limiter: rate.NewLimiter(limit, 1)}
+		c.hosts[name] = h
+	}
+
+	return h
+}
+
+// SetBudget ограничивает число запросов к хосту в день, включая повторы
+func (c *Client) SetBudget(name string, budget *Budget) {
+	h := c.host(name)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h.budget = budget
+}
+
+// spend расходует попытку из дневного бюджета хоста, если он задан
+func (c *Client) spend(ctx context.Context, name string, h *host) error {
+	c.mu.Lock()
+	budget := h.budget
+	c.mu.Unlock()
+	if budget == nil {
+		return nil
+	}
+
+	ok, err := budget.take()
+	if err != nil {
+		c.logger.WarnContext(ctx, "Error saving request quota", "host", name, "error", err)
+	}
+	metrics.SetQuotaRemaining(name, budget.Remaining())
+	if !ok {
+		return fmt.Errorf("%s: %w (%d requests a day)", name, ErrQuotaExceeded, budget.Limit())
+	}
+
+	return nil
+}
+
+// allow проверяет автомат защиты. После паузы BreakerCooldown пропускается один
+// пробный запрос: если он успешен, автомат замыкается.
+func (c *Client) allow(name string, h *host) error {
+	if c.opts.BreakerThreshold <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if h.failures < c.opts.BreakerThreshold {
+		return nil
+	}
+	if h.probing || time.Now().Before(h.openUntil) {
+		return fmt.Errorf("%s: %w", name, ErrCircuitOpen)
+	}
+	h.probing = true
+
+	return nil
+}
+
+// release снимает отметку пробного запроса, если он не был выполнен
+func (c *Client) release(h *host) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h.probing = false
+}
+
+// record учитывает результат попытки в состоянии автомата защиты и сообщает, разомкнут ли он
+func (c *Client) record(name string, h *host, failed bool) bool {
+	if c.opts.BreakerThreshold <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wasOpen := h.failures >= c.opts.BreakerThreshold
+	h.probing = false
+	if !failed {
+		h.failures = 0
+		if wasOpen {
+			metrics.SetCircuitOpen(name, false)
+			c.logger.Info("Circuit breaker closed", "host", name)
+		}
+		return false
+	}
+
+	h.failures++
+	if h.failures >= c.opts.BreakerThreshold {
+		h.openUntil = time.Now().Add(c.opts.BreakerCooldown)
+		if !wasOpen {
+			metrics.SetCircuitOpen(name, true)
+			c.logger.Warn("Circuit breaker opened", "host", name, "failures", h.failures, "cooldown", c.opts.BreakerCooldown)
+		}
+		return true
+	}
+
+	return false
+}
+
+// backoff возвращает паузу перед повтором: экспоненциальный рост от BaseDelay,
+// ограниченный MaxDelay, со случайным разбросом в верхней половине интервала
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.opts.BaseDelay << attempt
+	if delay <= 0 || delay > c.opts.MaxDelay {
+		delay = c.opts.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// classify определяет, стоит ли повторять попытку, и причину повтора для метрик
+func classify(resp *http.Response, err error) (retryable bool, reason string) {
+	if err != nil {
+		return true, "error"
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return true, strconv.Itoa(resp.StatusCode)
+	}
+
+	return false, ""
+}
+
+// retryAfter разбирает заголовок Retry-After: число секунд или дату HTTP
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// rewind возвращает запрос для очередной попытки с заново открытым телом
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body cannot be replayed for retry")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("error replaying request body: %w", err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+
+	return clone, nil
+}
+
+// sleep ждёт d или отмены ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,172 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestServer отвечает кодами из statuses по очереди, а после них — 200 OK
+func newTestServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func newTestClient(opts Options) *Client {
+	return New(opts, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// get выполняет GET-запрос и возвращает код ответа
+func get(t *testing.T, c *Client, url string) (int, error) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+func TestDoRetriesAfterRetryAfter(t *testing.T) {
+	server, requests := newTestServer(t, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests)
+	// Экспоненциальная пауза была бы не меньше 2,5 с, поэтому по времени видно, что учтён Retry-After
+	c := newTestClient(Options{MaxRetries: 2, BaseDelay: 5 * time.Second, MaxDelay: 5 * time.Second})
+
+	start := time.Now()
+	status, err := get(t, c, server.URL)
+	elapsed := time.Since(start)
+
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("Do = %d, %v, want 200", status, err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("server got %d requests, want 2", got)
+	}
+	if elapsed < time.Second || elapsed >= 2500*time.Millisecond {
+		t.Errorf("retry took %v, want about the 1s from Retry-After", elapsed)
+	}
+}
+
+func TestDoReturnsLongRetryAfter(t *testing.T) {
+	server, requests := newTestServer(t, http.Header{"Retry-After": {"3600"}}, http.StatusTooManyRequests)
+	c := newTestClient(Options{MaxRetries: 2, MaxDelay: time.Second})
+
+	status, err := get(t, c, server.URL)
+	if err != nil || status != http.StatusTooManyRequests {
+		t.Fatalf("Do = %d, %v, want 429", status, err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server got %d requests, want 1", got)
+	}
+}
+
+func TestDoExhaustsRetriesOnServerErrors(t *testing.T) {
+	server, requests := newTestServer(t, nil,
+		http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusInternalServerError, http.StatusServiceUnavailable)
+	c := newTestClient(Options{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+	status, err := get(t, c, server.URL)
+	if err != nil || status != http.StatusInternalServerError {
+		t.Fatalf("Do = %d, %v, want the last 500", status, err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("server got %d requests, want 3", got)
+	}
+}
+
+func TestDoDoesNotRetryClientErrors(t *testing.T) {
+	server, requests := newTestServer(t, nil, http.StatusBadRequest)
+	c := newTestClient(Options{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+	status, err := get(t, c, server.URL)
+	if err != nil || status != http.StatusBadRequest {
+		t.Fatalf("Do = %d, %v, want 400", status, err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server got %d requests, want 1", got)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	server, requests := newTestServer(t, nil, http.StatusInternalServerError, http.StatusInternalServerError)
+	cooldown := 50 * time.Millisecond
+	c := newTestClient(Options{BreakerThreshold: 2, BreakerCooldown: cooldown})
+
+	for i := range 2 {
+		if status, err := get(t, c, server.URL); err != nil || status != http.StatusInternalServerError {
+			t.Fatalf("request %d = %d, %v, want 500", i+1, status, err)
+		}
+	}
+
+	// Автомат разомкнут: запрос не доходит до сервера
+	if _, err := get(t, c, server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Do while open = %v, want ErrCircuitOpen", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("server got %d requests while the breaker was open, want 2", got)
+	}
+
+	// После паузы пробный запрос успешен, и автомат замыкается
+	time.Sleep(cooldown + 10*time.Millisecond)
+	for i := range 2 {
+		if status, err := get(t, c, server.URL); err != nil || status != http.StatusOK {
+			t.Fatalf("request %d after cooldown = %d, %v, want 200", i+1, status, err)
+		}
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("server got %d requests, want 4", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.value != "" {
+			resp.Header.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(resp, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded возвращается без обращения к хосту, когда дневной бюджет запросов исчерпан
+var ErrQuotaExceeded = errors.New("daily request quota exceeded")
+
+// Budget — дневной бюджет запросов к одному хосту. День считается по UTC.
+// Если задан файл, расход сохраняется в нём и переживает перезапуск бота.
+type Budget struct {
+	limit int
+	path  string
+
+	mu   sync.Mutex
+	day  string
+	used int
+}
+
+// budgetState — содержимое файла бюджета
+type budgetState struct {
+	Day  string `json:"day"`
+	Used int    `json:"used"`
+}
+
+// NewBudget создаёт бюджет на limit запросов в день; limit 0 снимает ограничение.
+// Пустой path — расход хранится только в памяти. Отсутствующий файл создаётся сразу,
+// чтобы недоступный для записи путь обнаружился при запуске, а не при первом запросе.
+func NewBudget(limit int, path string) (*Budget, error) {
+	b := &Budget{limit: limit, path: path, day: today()}
+	if path == "" {
+		return b, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := b.save(); err != nil {
+			return nil, fmt.Errorf("error creating quota file: %w", err)
+		}
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading quota file: %w", err)
+	}
+
+	var state budgetState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error decoding quota file %s: %w", path, err)
+	}
+	if state.Day == b.day {
+		b.used = state.Used
+	}
+
+	return b, nil
+}
+
+// Limit возвращает дневной лимит; 0 — без ограничения
+func (b *Budget) Limit() int {
+	return b.limit
+}
+
+// Remaining возвращает число запросов, оставшихся на сегодня, или -1 без ограничения
+func (b *Budget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rollover()
+	if b.limit == 0 {
+		return -1
+	}
+
+	return max(b.limit-b.used, 0)
+}
+
+// take расходует один запрос из бюджета. ok равен false, если бюджет исчерпан;
+// err — ошибка сохранения расхода, которая не мешает выполнить запрос.
+func (b *Budget) take() (ok bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rollover()
+	if b.limit > 0 && b.used >= b.limit {
+		return false, nil
+	}
+	b.used++
+
+	return true, b.save()
+}
+
+// rollover обнуляет расход с наступлением нового дня
+func (b *Budget) rollover() {
+	if day := today(); day != b.day {
+		b.day = day
+		b.used = 0
+	}
+}
+
+func (b *Budget) save() error {
+	if b.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(budgetState{Day: b.day, Used: b.used})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return fmt.Errorf("error creating quota directory: %w", err)
+	}
+
+	return writeFile(b.path, data)
+}
+
+func today() string {
+	return time.Now().UTC().Format(time.DateOnly)
+}
+
+// writeFile записывает файл через временный, чтобы при сбое не остался обрезанный файл
+func writeFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,43 @@
+package httpclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewBudgetCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota", "newsapi.org.json")
+	budget, err := NewBudget(2, path)
+	if err != nil {
+		t.Fatalf("NewBudget: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("quota file was not created: %v", err)
+	}
+
+	if ok, err := budget.take(); !ok || err != nil {
+		t.Fatalf("take = %v, %v, want true, nil", ok, err)
+	}
+
+	// Расход переживает перезапуск
+	reopened, err := NewBudget(2, path)
+	if err != nil {
+		t.Fatalf("NewBudget after restart: %v", err)
+	}
+	if got := reopened.Remaining(); got != 1 {
+		t.Errorf("Remaining after restart = %d, want 1", got)
+	}
+}
+
+func TestNewBudgetUnwritablePath(t *testing.T) {
+	// Каталог квоты не создать: на его месте обычный файл
+	file := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewBudget(100, filepath.Join(file, "quota", "newsapi.org.json")); err == nil {
+		t.Fatal("NewBudget succeeded with an unwritable path")
+	}
+}
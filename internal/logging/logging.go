@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Форматы вывода журнала
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Имена атрибутов, общие для всех пакетов: по run_id можно найти все записи одного запуска конвейера
+const (
+	RunID      = "run_id"
+	ChatID     = "chat_id"
+	ArticleURL = "article_url"
+	Source     = "source"
+	Error      = "error"
+	TraceID    = "trace_id"
+	SpanID     = "span_id"
+)
+
+// New создаёт журнал в формате text или json. Уровнем можно управлять во время работы через level.
+func New(w io.Writer, format string, level *slog.LevelVar) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected text or json", format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// ParseLevel разбирает уровень журнала: debug, info, warn или error
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", level)
+	}
+
+	return l, nil
+}
+
+type contextKey struct{}
+
+// With возвращает контекст, записи журнала с которым получают атрибуты args
+func With(ctx context.Context, args ...any) context.Context {
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+
+	r := slog.Record{}
+	r.Add(args...)
+	next := make([]slog.Attr, 0, len(attrs)+r.NumAttrs())
+	next = append(next, attrs...)
+	r.Attrs(func(attr slog.Attr) bool {
+		next = append(next, attr)
+		return true
+	})
+
+	return context.WithValue(ctx, contextKey{}, next)
+}
+
+// NewRunID возвращает случайный идентификатор запуска конвейера
+func NewRunID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// contextHandler добавляет к записи атрибуты, сохранённые в контексте функцией With,
+// и идентификаторы трассы и спана, чтобы от записи журнала можно было перейти к трассе
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(contextKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String(TraceID, sc.TraceID().String()), slog.String(SpanID, sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,196 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Метрики бота в формате Prometheus. Регистрируются в реестре по умолчанию
+// и отдаются сервером из server.go по адресу /metrics.
+
+const namespace = "technews"
+
+var (
+	pipelineRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pipeline_runs_total",
+		Help:      "News pipeline runs by result.",
+	}, []string{"result"})
+
+	pipelineDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "pipeline_duration_seconds",
+		Help:      "Duration of news pipeline runs, including moderation.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 900, 1800, 3600, 7200},
+	})
+
+	newsAPIRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "newsapi_requests_total",
+		Help:      "NewsAPI requests by HTTP status code; \"error\" means the request failed before a response.",
+	}, []string{"status"})
+
+	newsAPIDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "newsapi_request_duration_seconds",
+		Help:      "Latency of NewsAPI requests.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	openAIRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "openai_requests_total",
+		Help:      "OpenAI chat completion requests by operation and result.",
+	}, []string{"operation", "result"})
+
+	openAIDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "openai_request_duration_seconds",
+		Help:      "Latency of OpenAI chat completion requests.",
+		Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60},
+	}, []string{"operation"})
+
+	openAITokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "openai_tokens_total",
+		Help:      "OpenAI tokens used by operation and kind (prompt or completion).",
+	}, []string{"operation", "kind"})
+
+	messagesSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_sent_total",
+		Help:      "Broadcast messages delivered by chat type.",
+	}, []string{"chat_type"})
+
+	messagesFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_failed_total",
+		Help:      "Broadcast messages that failed by chat type and error class.",
+	}, []string{"chat_type", "reason"})
+
+	httpRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_retries_total",
+		Help:      "Retried requests to news sources by host and reason (status code or \"error\").",
+	}, []string{"host", "reason"})
+
+	httpCircuitOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_circuit_open",
+		Help:      "1 while the circuit breaker for a news source host is open, 0 otherwise.",
+	}, []string{"host"})
+
+	quotaRemaining = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_quota_remaining",
+		Help:      "Requests left today in the daily budget of a news source host.",
+	}, []string{"host"})
+
+	articlesFiltered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "articles_filtered_total",
+		Help:      "Fetched articles dropped by content filters, by filter.",
+	}, []string{"filter"})
+)
+
+// ObservePipeline записывает результат и длительность запуска конвейера
+func ObservePipeline(start time.Time, err error) {
+	pipelineDuration.Observe(time.Since(start).Seconds())
+	pipelineRuns.WithLabelValues(result(err)).Inc()
+}
+
+// ObserveNewsAPI записывает запрос к NewsAPI; status равен 0, если ответ не получен
+func ObserveNewsAPI(start time.Time, status int) {
+	newsAPIDuration.Observe(time.Since(start).Seconds())
+
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	newsAPIRequests.WithLabelValues(label).Inc()
+}
+
+// ObserveOpenAI записывает запрос к OpenAI и израсходованные токены
+func ObserveOpenAI(operation string, start time.Time, promptTokens, completionTokens int, err error) {
+	openAIDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	openAIRequests.WithLabelValues(operation, result(err)).Inc()
+	openAITokens.WithLabelValues(operation, "prompt").Add(float64(promptTokens))
+	openAITokens.WithLabelValues(operation, "completion").Add(float64(completionTokens))
+}
+
+// MessageSent записывает доставленное сообщение рассылки
+func MessageSent(chatType string) {
+	messagesSent.WithLabelValues(chatType).Inc()
+}
+
+// MessageFailed записывает сообщение рассылки, которое не удалось доставить
+func MessageFailed(chatType, reason string) {
+	messagesFailed.WithLabelValues(chatType, reason).Inc()
+}
+
+// HTTPRetry записывает повтор запроса к источнику новостей
+func HTTPRetry(host, reason string) {
+	httpRetries.WithLabelValues(host, reason).Inc()
+}
+
+// SetCircuitOpen записывает состояние автомата защиты для хоста источника
+func SetCircuitOpen(host string, open bool) {
+	value := 0.0
+	if open {
+		value = 1
+	}
+	httpCircuitOpen.WithLabelValues(host).Set(value)
+}
+
+// SetQuotaRemaining записывает остаток дневного бюджета запросов к хосту; -1 — без ограничения
+func SetQuotaRemaining(host string, remaining int) {
+	if remaining < 0 {
+		quotaRemaining.DeleteLabelValues(host)
+		return
+	}
+	quotaRemaining.WithLabelValues(host).Set(float64(remaining))
+}
+
+// ArticleFiltered записывает статью, отброшенную фильтром
+func ArticleFiltered(filter string) {
+	articlesFiltered.WithLabelValues(filter).Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// subscribersCollector считает подписчиков в момент запроса метрик,
+// поэтому значение не расходится с хранилищем
+type subscribersCollector struct {
+	desc  *prometheus.Desc
+	count func() map[string]int
+}
+
+// RegisterSubscribers регистрирует метрику числа активных получателей рассылки по типам чатов
+func RegisterSubscribers(count func() map[string]int) {
+	prometheus.MustRegister(&subscribersCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "subscribers"),
+			"Active delivery targets by chat type.",
+			[]string{"chat_type"}, nil,
+		),
+		count: count,
+	})
+}
+
+func (c *subscribersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *subscribersCollector) Collect(ch chan<- prometheus.Metric) {
+	for chatType, count := range c.count() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), chatType)
+	}
+}
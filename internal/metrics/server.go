@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// readyTimeout ограничивает время одной проверки готовности
+const readyTimeout = 5 * time.Second
+
+// Check проверяет доступность зависимости, например Telegram или базы данных
+type Check func(ctx context.Context) error
+
+// Serve запускает HTTP-сервер с /healthz, /readyz и /metrics и останавливает его
+// при отмене ctx. /healthz отвечает, пока процесс работает, /readyz — когда все
+// проверки checks проходят.
+func Serve(ctx context.Context, addr string, checks map[string]Check, logger *slog.Logger) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		readyHandler(w, r, checks)
+	})
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Metrics and health endpoints listening", "addr", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving metrics: %w", err)
+	}
+
+	return nil
+}
+
+// readyHandler выполняет проверки и перечисляет результат каждой
+func readyHandler(w http.ResponseWriter, r *http.Request, checks map[string]Check) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ready := true
+	var sb strings.Builder
+	for _, name := range names {
+		if err := checks[name](ctx); err != nil {
+			ready = false
+			sb.WriteString(fmt.Sprintf("%s: %v\n", name, err))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s: ok\n", name))
+	}
+
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	fmt.Fprint(w, sb.String())
+}
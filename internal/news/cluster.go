@@ -0,0 +1,182 @@
+package news
+
+import (
+	"hash/fnv"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Cluster — одна история, о которой написали несколько изданий
+type Cluster struct {
+	Articles []*Article
+}
+
+// Sources возвращает издания, написавшие об истории, без повторов
+func (c *Cluster) Sources() []string {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, article := range c.Articles {
+		source := sourceName(article)
+		if source != "" && !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+
+	return sources
+}
+
+// sourceName возвращает название издания, а если NewsAPI его не прислал — хост статьи
+func sourceName(article *Article) string {
+	if article.Source.Name != "" {
+		return article.Source.Name
+	}
+	if u, err := url.Parse(article.URL); err == nil {
+		return strings.TrimPrefix(u.Hostname(), "www.")
+	}
+
+	return ""
+}
+
+// minHashSize — число хэш-функций в подписи MinHash. Ошибка оценки сходства около 1/√128 ≈ 0,09.
+const minHashSize = 128
+
+// signature — подпись MinHash множества шинглов статьи
+type signature [minHashSize]uint64
+
+// clusterArticles объединяет статьи об одной истории. Статьи сравниваются по подписям
+// MinHash шинглов текста: статья попадает в кластер, если оценка коэффициента Жаккара
+// с представителем кластера — его первой статьёй — не меньше threshold. Сравнение
+// с представителем, а не с любой статьёй кластера, не даёт цепочке похожих пар склеить
+// разные истории. threshold 0 отключает объединение. Кандидатов немного, поэтому
+// подписи сравниваются попарно.
+func clusterArticles(articles []*Article, threshold float64) []*Cluster {
+	// Кластеры идут в порядке первой статьи, статьи в кластере — в исходном порядке
+	var clusters []*Cluster
+	var representatives []*signature
+	for _, article := range articles {
+		var sig *signature
+		if threshold > 0 {
+			sig = minHash(shingles(article))
+		}
+
+		best, bestSimilarity := -1, threshold
+		for i, representative := range representatives {
+			if sig == nil || representative == nil {
+				continue
+			}
+			if s := similarity(sig, representative); s >= bestSimilarity {
+				best, bestSimilarity = i, s
+			}
+		}
+
+		if best < 0 {
+			clusters = append(clusters, &Cluster{Articles: []*Article{article}})
+			representatives = append(representatives, sig)
+			continue
+		}
+		clusters[best].Articles = append(clusters[best].Articles, article)
+	}
+
+	return clusters
+}
+
+// shingleSize — число слов в шингле. Пары слов учитывают порядок: статьи, где просто
+// встречаются одни и те же частые слова (Apple, AI, update), перестают выглядеть похожими.
+const shingleSize = 2
+
+// truncatedContent — пометка, которой NewsAPI обрезает текст статьи: «… [+2345 chars]»
+var truncatedContent = regexp.MustCompile(`\s*\[\+\d+ chars\]$`)
+
+// shingles возвращает шинглы — последовательности из shingleSize значимых слов подряд —
+// заголовка, описания и текста статьи. Из заголовка убирается название издания,
+// которое NewsAPI добавляет в конце: «Title - The Verge». Если значимых слов меньше
+// shingleSize, шинглом становится весь текст.
+func shingles(article *Article) map[string]bool {
+	title := article.Title
+	if article.Source.Name != "" {
+		title = strings.TrimSuffix(title, " - "+article.Source.Name)
+	}
+	content := truncatedContent.ReplaceAllString(article.Content, "")
+
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(title+"\n"+article.Description+"\n"+content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) > 1 && !stopWords[word] {
+			words = append(words, word)
+		}
+	}
+
+	result := make(map[string]bool)
+	if len(words) > 0 && len(words) < shingleSize {
+		result[strings.Join(words, " ")] = true
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		result[strings.Join(words[i:i+shingleSize], " ")] = true
+	}
+
+	return result
+}
+
+// minHash вычисляет подпись множества шинглов; для пустого множества возвращает nil
+func minHash(shingles map[string]bool) *signature {
+	if len(shingles) == 0 {
+		return nil
+	}
+
+	var sig signature
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+
+	for shingle := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		base := h.Sum64()
+		for i := range sig {
+			if v := mix(base ^ uint64(i+1)*0x9e3779b97f4a7c15); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+
+	return &sig
+}
+
+// similarity оценивает коэффициент Жаккара по доле совпавших минимумов
+func similarity(a, b *signature) float64 {
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+
+	return float64(same) / minHashSize
+}
+
+// mix — финальное перемешивание splitmix64: из одного хэша слова получается
+// семейство независимых хэш-функций
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// stopWords — частые английские слова, которые не отличают одну историю от другой
+var stopWords = map[string]bool{
+	"an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "has": true, "have": true, "how": true, "in": true,
+	"is": true, "it": true, "its": true, "new": true, "of": true, "on": true, "or": true,
+	"says": true, "that": true, "the": true, "this": true, "to": true, "was": true, "what": true,
+	"why": true, "will": true, "with": true, "you": true, "your": true, "after": true,
+	"about": true, "now": true, "can": true, "more": true, "than": true, "we": true,
+	"our": true, "their": true, "they": true, "all": true, "just": true, "up": true,
+	"out": true, "into": true, "over": true, "who": true, "not": true, "but": true,
+}
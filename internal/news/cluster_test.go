@@ -0,0 +1,141 @@
+package news
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func testArticle(source, title, description, content string) *Article {
+	article := &Article{Title: title, Description: description, Content: content}
+	article.Source.Name = source
+	return article
+}
+
+// wordsArticle возвращает статью из слов w<from>…w<to>: у статей с пересекающимися
+// диапазонами общие шинглы, остальные различаются
+func wordsArticle(source string, from, to int) *Article {
+	var words []string
+	for i := from; i <= to; i++ {
+		words = append(words, fmt.Sprintf("w%02d", i))
+	}
+	return testArticle(source, strings.Join(words, " "), "", "")
+}
+
+// Переписанная изданиями новость, перепечатка агентской заметки и несвязанная статья
+var (
+	macbookVerge = testArticle("The Verge",
+		"Apple announces M4 MacBook Pro with Thunderbolt 5 - The Verge",
+		"Apple has announced new MacBook Pro models with M4, M4 Pro and M4 Max chips, Thunderbolt 5 ports and a nano-texture display option.",
+		"Apple has announced new MacBook Pro models powered by its M4, M4 Pro and M4 Max chips. The laptops start at $1,599 and ship November 8th… [+2345 chars]")
+	macbookEngadget = testArticle("Engadget",
+		"Apple's new MacBook Pro gets M4 Pro and M4 Max chips and Thunderbolt 5",
+		"Apple announced new MacBook Pro models with M4, M4 Pro and M4 Max chips and Thunderbolt 5 ports.",
+		"Apple today announced new MacBook Pro models with M4, M4 Pro and M4 Max chips, starting at $1,599 and shipping November 8th. … [+3000 chars]")
+	fundingAP = testArticle("AP",
+		"OpenAI raises $6.6 billion in funding at $157 billion valuation",
+		"OpenAI said it raised $6.6 billion in new funding that values the ChatGPT maker at $157 billion.",
+		"SAN FRANCISCO (AP) — OpenAI said Wednesday it raised $6.6 billion in new funding that values the ChatGPT maker at $157 billion. … [+2000 chars]")
+	fundingABC = testArticle("ABC News",
+		"OpenAI raises $6.6 billion in funding at $157 billion valuation",
+		"OpenAI said it raised $6.6 billion in new funding that values the ChatGPT maker at $157 billion.",
+		"SAN FRANCISCO (AP) — OpenAI said Wednesday it raised $6.6 billion in new funding that values the ChatGPT maker at $157 billion, … [+2100 chars]")
+	iosWired = testArticle("Wired",
+		"Apple releases iOS 18.1 with Apple Intelligence features",
+		"Apple has released iOS 18.1, the first update with Apple Intelligence features such as writing tools and a new Siri design.",
+		"Apple has released iOS 18.1 to iPhone users, bringing the first Apple Intelligence features. … [+4000 chars]")
+)
+
+func TestShingles(t *testing.T) {
+	article := testArticle("The Verge", "Apple ships the M4 - The Verge", "", "New chips… [+1200 chars]")
+	got := shingles(article)
+
+	for _, want := range []string{"apple ships", "ships m4", "m4 chips"} {
+		if !got[want] {
+			t.Errorf("shingles() = %v, missing %q", got, want)
+		}
+	}
+	for shingle := range got {
+		if strings.Contains(shingle, "verge") || strings.Contains(shingle, "chars") || strings.Contains(shingle, "the") {
+			t.Errorf("shingles() contains %q: source name, truncation marker and stop words are not significant", shingle)
+		}
+	}
+
+	if got := shingles(testArticle("", "Apple", "", "")); len(got) != 1 || !got["apple"] {
+		t.Errorf("shingles() of one word = %v, want the word itself", got)
+	}
+	if got := shingles(testArticle("", "The", "", "")); len(got) != 0 {
+		t.Errorf("shingles() of stop words = %v, want none", got)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     *Article
+		min, max float64
+	}{
+		{"identical", macbookVerge, macbookVerge, 1, 1},
+		{"agency copy", fundingAP, fundingABC, 0.8, 1},
+		{"same story retold", macbookVerge, macbookEngadget, 0.2, 0.5},
+		{"same company, different story", macbookVerge, iosWired, 0, 0.05},
+		{"unrelated", fundingAP, iosWired, 0, 0.05},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := similarity(minHash(shingles(tt.a)), minHash(shingles(tt.b)))
+			if got < tt.min || got > tt.max {
+				t.Errorf("similarity() = %.2f, want between %.2f and %.2f", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestClusterArticles(t *testing.T) {
+	tests := []struct {
+		name      string
+		articles  []*Article
+		threshold float64
+		want      [][]string
+	}{
+		{
+			name:      "near duplicates are one story",
+			articles:  []*Article{macbookVerge, fundingAP, iosWired, macbookEngadget, fundingABC},
+			threshold: 0.2,
+			want:      [][]string{{"The Verge", "Engadget"}, {"AP", "ABC News"}, {"Wired"}},
+		},
+		{
+			name:      "threshold 0 disables clustering",
+			articles:  []*Article{fundingAP, fundingABC},
+			threshold: 0,
+			want:      [][]string{{"AP"}, {"ABC News"}},
+		},
+		{
+			// Соседние статьи похожи, а первая и третья нет: без сравнения с представителем
+			// цепочка склеила бы все три
+			name:      "similarity does not chain",
+			articles:  []*Article{wordsArticle("A", 1, 10), wordsArticle("B", 6, 15), wordsArticle("C", 11, 20)},
+			threshold: 0.2,
+			want:      [][]string{{"A", "B"}, {"C"}},
+		},
+		{
+			name:      "articles without significant words stay apart",
+			articles:  []*Article{testArticle("A", "The", "", ""), testArticle("B", "The", "", "")},
+			threshold: 0.2,
+			want:      [][]string{{"A"}, {"B"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, cluster := range clusterArticles(tt.articles, tt.threshold) {
+				got = append(got, cluster.Sources())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("clusterArticles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
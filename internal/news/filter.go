@@ -0,0 +1,159 @@
+package news
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/metrics"
+)
+
+// Причины, по которым фильтр отбрасывает статью: по ним ведутся метрика и журнал
+const (
+	FilterRemoved          = "removed"
+	FilterBlockedDomain    = "blocked_domain"
+	FilterNotAllowedDomain = "not_allowed_domain"
+	FilterTitlePattern     = "title_pattern"
+	FilterTooShort         = "too_short"
+	FilterTooOld           = "too_old"
+	FilterLanguage         = "language"
+)
+
+// removedTitle — заглушка, которую NewsAPI возвращает вместо удалённых статей
+const removedTitle = "[Removed]"
+
+// FilterOptions — фильтры, которые применяются к статьям сразу после получения
+type FilterOptions struct {
+	// AllowDomains — если задан, остаются только статьи с этих доменов и их поддоменов
+	AllowDomains []string
+	// BlockDomains — домены, статьи с которых отбрасываются
+	BlockDomains []string
+	// TitlePatterns — регулярные выражения; статьи с подходящим заголовком отбрасываются
+	TitlePatterns []string
+	// MinContentLength — минимальная длина описания и текста в символах; 0 отключает проверку
+	MinContentLength int
+	// MaxAge — максимальный возраст статьи; 0 отключает проверку
+	MaxAge time.Duration
+	// DetectLanguage — отбрасывать статьи, язык которых уверенно определён как другой
+	DetectLanguage bool
+}
+
+// filter — фильтры статей с разобранными регулярными выражениями
+type filter struct {
+	opts          FilterOptions
+	titlePatterns []*regexp.Regexp
+}
+
+// newFilter разбирает регулярные выражения из opts. Выражения проверяются при загрузке
+// конфигурации, поэтому ошибочные здесь только пропускаются с записью в журнал.
+func newFilter(opts FilterOptions, logger *slog.Logger) *filter {
+	f := &filter{opts: opts}
+	for _, pattern := range opts.TitlePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			logger.Warn("Skipping invalid title filter", "pattern", pattern, logging.Error, err)
+			continue
+		}
+		f.titlePatterns = append(f.titlePatterns, re)
+	}
+
+	return f
+}
+
+// reason возвращает причину, по которой статья отбрасывается, или пустую строку
+func (f *filter) reason(article *Article, language string, now time.Time) string {
+	if strings.TrimSpace(article.Title) == removedTitle || article.URL == "https://removed.com" {
+		return FilterRemoved
+	}
+
+	host := articleHost(article)
+	if matchesDomain(host, f.opts.BlockDomains) {
+		return FilterBlockedDomain
+	}
+	if len(f.opts.AllowDomains) > 0 && !matchesDomain(host, f.opts.AllowDomains) {
+		return FilterNotAllowedDomain
+	}
+
+	for _, re := range f.titlePatterns {
+		if re.MatchString(article.Title) {
+			return FilterTitlePattern
+		}
+	}
+
+	if f.opts.MinContentLength > 0 && utf8.RuneCountInString(strings.TrimSpace(article.Description+" "+article.Content)) < f.opts.MinContentLength {
+		return FilterTooShort
+	}
+
+	if f.opts.MaxAge > 0 && !article.PublishedAt.IsZero() && now.Sub(article.PublishedAt) > f.opts.MaxAge {
+		return FilterTooOld
+	}
+
+	if f.opts.DetectLanguage && language != "" && !matchesLanguage(article.Title+"\n"+article.Description, language) {
+		return FilterLanguage
+	}
+
+	return ""
+}
+
+// filterArticles отбрасывает статьи, не прошедшие фильтры. Каждое решение пишется
+// в журнал на уровне debug, итог по причинам — на уровне info, чтобы фильтры было
+// удобно настраивать.
+func (c *Client) filterArticles(ctx context.Context, articles []Article, language string) []Article {
+	now := time.Now()
+	kept := articles[:0]
+	dropped := make(map[string]int)
+	for i := range articles {
+		article := &articles[i]
+		reason := c.filter.reason(article, language, now)
+		if reason == "" {
+			kept = append(kept, *article)
+			continue
+		}
+
+		dropped[reason]++
+		metrics.ArticleFiltered(reason)
+		c.logger.DebugContext(ctx, "Article filtered out", "filter", reason, "title", article.Title, logging.ArticleURL, article.URL)
+	}
+
+	if len(dropped) > 0 {
+		attrs := []any{"kept", len(kept), "dropped", len(articles) - len(kept)}
+		for _, reason := range []string{FilterRemoved, FilterBlockedDomain, FilterNotAllowedDomain, FilterTitlePattern, FilterTooShort, FilterTooOld, FilterLanguage} {
+			if dropped[reason] > 0 {
+				attrs = append(attrs, reason, dropped[reason])
+			}
+		}
+		c.logger.InfoContext(ctx, "Articles filtered", attrs...)
+	}
+
+	return kept
+}
+
+// articleHost возвращает домен статьи в нижнем регистре без «www.»
+func articleHost(article *Article) string {
+	u, err := url.Parse(article.URL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// matchesDomain сообщает, совпадает ли host с одним из доменов или является его поддоменом
+func matchesDomain(host string, domains []string) bool {
+	if host == "" {
+		return false
+	}
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+
+	return false
+}
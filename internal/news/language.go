@@ -0,0 +1,117 @@
+package news
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageScripts — языки NewsAPI с собственной письменностью. Урду пишется арабским
+// письмом, поэтому по письменности его от арабского не отличить.
+var languageScripts = map[string]*unicode.RangeTable{
+	"ar": unicode.Arabic,
+	"ud": unicode.Arabic,
+	"he": unicode.Hebrew,
+	"ru": unicode.Cyrillic,
+	"zh": unicode.Han,
+}
+
+// languageStopWords — частые служебные слова языков NewsAPI на латинице
+var languageStopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "for", "with", "on", "are", "this", "was", "from", "by", "has", "have", "it", "its", "will"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "ein", "eine", "auf", "für", "den", "von", "zu", "sich", "des", "dem", "im", "auch", "wird"},
+	"es": {"el", "la", "los", "las", "que", "y", "en", "un", "una", "por", "para", "con", "es", "se", "del", "al", "su", "como", "más", "pero"},
+	"fr": {"le", "la", "les", "des", "et", "est", "une", "un", "du", "dans", "pour", "que", "qui", "sur", "pas", "au", "avec", "ce", "il", "sont"},
+	"it": {"il", "la", "di", "che", "e", "è", "un", "una", "per", "con", "non", "del", "della", "sono", "gli", "nel", "alla", "anche", "più", "le"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "op", "te", "niet", "met", "voor", "zijn", "ook", "aan", "er", "bij", "naar", "om", "maar"},
+	"no": {"og", "i", "er", "det", "som", "på", "en", "til", "av", "for", "med", "har", "ikke", "et", "den", "å", "de", "om", "var", "kan"},
+	"pt": {"o", "a", "os", "as", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "com", "não", "é", "no", "na", "se", "por"},
+	"sv": {"och", "i", "att", "det", "som", "en", "är", "på", "för", "med", "av", "till", "den", "har", "inte", "om", "ett", "de", "var", "kan"},
+}
+
+// minLanguageHits — сколько служебных слов нужно, чтобы уверенно определить язык
+const minLanguageHits = 3
+
+// Для языка со своей письменностью текст на латинице считается написанным на другом языке,
+// только если в нём не меньше minLatinLetters букв. Короткие заголовки из названий
+// брендов и продуктов — «iPhone 17», «OpenAI GPT-5» — встречаются на любом языке.
+// Уже minScriptLetters букв своей письменности делают текст смешанным, а не чужим.
+const (
+	minLatinLetters  = 40
+	minScriptLetters = 3
+)
+
+// matchesLanguage сообщает, может ли текст быть написан на языке language. Если язык
+// определить не удалось — текст короткий или язык не из списка, — ответ положительный:
+// фильтр отбрасывает только статьи, язык которых уверенно определён как другой.
+func matchesLanguage(text, language string) bool {
+	// Письменность: статья на кириллице не может быть английской, и наоборот
+	letters, counts := 0, make(map[*unicode.RangeTable]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range languageScripts {
+			if unicode.Is(script, r) {
+				counts[script]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return true
+	}
+	for script, count := range counts {
+		if count*2 > letters {
+			return languageScripts[language] == script
+		}
+	}
+	if script := languageScripts[language]; script != nil {
+		// Ожидается своя письменность, а текст в основном на латинице: это может быть
+		// смешанный заголовок или одно название, поэтому нужны дополнительные признаки
+		if counts[script] >= minScriptLetters {
+			return true
+		}
+		return detectLatinLanguage(text) == "" && letters < minLatinLetters
+	}
+
+	detected := detectLatinLanguage(text)
+	return detected == "" || detected == language
+}
+
+// detectLatinLanguage определяет язык текста на латинице по служебным словам.
+// Возвращает пустую строку, если ни один язык не набрал minLanguageHits совпадений
+// или два языка набрали поровну.
+func detectLatinLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	hits := make(map[string]int)
+	for language, stopWords := range languageStopWords {
+		set := make(map[string]bool, len(stopWords))
+		for _, word := range stopWords {
+			set[word] = true
+		}
+		for _, word := range words {
+			if set[word] {
+				hits[language]++
+			}
+		}
+	}
+
+	best, bestHits, tie := "", 0, false
+	for language, count := range hits {
+		switch {
+		case count > bestHits:
+			best, bestHits, tie = language, count, false
+		case count == bestHits:
+			tie = true
+		}
+	}
+	if bestHits < minLanguageHits || tie {
+		return ""
+	}
+
+	return best
+}
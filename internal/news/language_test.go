@@ -0,0 +1,50 @@
+package news
+
+import "testing"
+
+func TestMatchesLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		language string
+		want     bool
+	}{
+		{"empty text", "", "ru", true},
+		{"digits only", "2025", "en", true},
+
+		// Короткие заголовки из названий брендов ничего не говорят о языке
+		{"brand only for ru", "iPhone 17", "ru", true},
+		{"brand only for ar", "OpenAI GPT-5", "ar", true},
+		{"brand only for zh", "iPhone 17 Pro Max", "zh", true},
+		{"brand only for en", "iPhone 17", "en", true},
+		{"short Latin title for he", "Nvidia GeForce RTX 5090", "he", true},
+
+		// Смешанная письменность: названия на латинице внутри заголовка на своём языке
+		{"mixed script with Latin majority", "Apple выпустила iPhone 17 Pro Max", "ru", true},
+		{"mixed script with Cyrillic majority", "OpenAI представила GPT-5", "ru", true},
+		{"mixed script for zh", "苹果发布 iPhone 17 Pro Max", "zh", true},
+
+		// Уверенно определённый другой язык
+		{"English sentence for ru", "Apple has released the new iPhone and it is available for preorder", "ru", false},
+		{"long Latin text for ru", "Nvidia GeForce RTX 5090 Founders Edition review: benchmarks, thermals, pricing, availability", "ru", false},
+		{"Cyrillic for en", "Яндекс представил новую нейросеть", "en", false},
+		{"Arabic for he", "أعلنت شركة أبل عن هاتف جديد", "he", false},
+		{"German for en", "Die neue Version ist nicht mit der alten kompatibel und wird im Herbst erscheinen", "en", false},
+		{"English for de", "The company said that the update is available for all users from today", "de", false},
+
+		// Свой язык
+		{"Russian", "Яндекс представил новую нейросеть", "ru", true},
+		{"Hebrew", "אפל הכריזה על אייפון חדש", "he", true},
+		{"English", "The company said that the update is available for all users", "en", true},
+		{"German", "Die neue Version ist nicht mit der alten kompatibel", "de", true},
+		{"undetected Latin language", "Nvidia GeForce RTX 5090 Founders Edition", "fr", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesLanguage(tt.text, tt.language); got != tt.want {
+				t.Errorf("matchesLanguage(%q, %q) = %v, want %v", tt.text, tt.language, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,31 @@
 package news
 
 import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
-	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/andrei/goBot/internal/httpclient"
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/metrics"
+	"github.com/andrei/goBot/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/andrei/goBot/internal/news")
+
 type Article struct {
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
@@ -20,6 +36,44 @@ type Article struct {
 		Name string `json:"name"`
 	} `json:"source"`
 	Author string `json:"author"`
+
+	// Coverage — другие издания, написавшие о той же истории
+	Coverage []string `json:"-"`
+	// Score — оценка статьи при выборе с разбором по признакам
+	Score *Score `json:"-"`
+}
+
+// ID возвращает короткий стабильный идентификатор статьи, вычисленный по её URL
+func (a *Article) ID() string {
+	sum := sha1.Sum([]byte(a.URL))
+	return hex.EncodeToString(sum[:8])
+}
+
+// techKeywords — технологические темы, по которым оцениваются статьи
+var techKeywords = []string{"technology", "tech", "software", "AI", "artificial intelligence", 
+	"cybersecurity", "digital", "innovation", "startup", "algorithm", "cloud", "data", 
+	"security", "privacy", "blockchain", "machine learning"}
+
+// Topics возвращает технологические темы, упомянутые в заголовке и описании статьи
+func (a *Article) Topics() []string {
+	var topics []string
+
+	combinedText := strings.ToLower(a.Title + " " + a.Description)
+	for _, keyword := range techKeywords {
+		if strings.Contains(combinedText, strings.ToLower(keyword)) {
+			topics = append(topics, keyword)
+		}
+	}
+
+	return topics
+}
+
+// Preferences — предпочтения аудитории, собранные из отзывов подписчиков.
+// Значения находятся в диапазоне [-1, 1]: положительные — аудитории нравится, отрицательные — нет.
+type Preferences struct {
+	Sources  map[string]float64
+	Topics   map[string]float64
+	Keywords map[string]float64
 }
 
 type NewsAPIResponse struct {
@@ -28,81 +82,218 @@ type NewsAPIResponse struct {
 	Articles     []Article `json:"articles"`
 }
 
+// APIHost — хост NewsAPI, для которого действует дневной бюджет запросов
+const APIHost = "newsapi.org"
+
+// Options — настройки клиента NewsAPI
+type Options struct {
+	// Query — параметры запроса статей
+	Query Query
+	// ClusterThreshold — сходство статей, начиная с которого они считаются одной историей;
+	// 0 отключает объединение
+	ClusterThreshold float64
+	// Scoring — веса и настройки признаков оценки статей
+	Scoring ScoringOptions
+	// Ranking — уточнение порядка лучших статей через Ranker
+	Ranking RankingOptions
+	// Filters — фильтры, которые отбрасывают статьи сразу после получения
+	Filters FilterOptions
+}
+
 type Client struct {
 	apiKey     string
-	httpClient *http.Client
+	opts       Options
+	scorer     *Scorer
+	filter     *filter
+	httpClient httpclient.Doer
+	quota      *httpclient.Budget
+	logger     *slog.Logger
+
+	mu          sync.RWMutex
+	preferences *Preferences
+	ranker      Ranker
 }
 
-func NewClient(apiKey string) *Client {
+// NewClient создаёт клиент NewsAPI с настройками opts. httpClient общий для всех источников: через него
+// действуют кэш, повторы, ограничение частоты и автомат защиты. quota — дневной бюджет
+// запросов к NewsAPI, остаток которого попадает в журнал; nil — без учёта.
+func NewClient(apiKey string, opts Options, httpClient httpclient.Doer, quota *httpclient.Budget, logger *slog.Logger) *Client {
+	logger = logger.With("component", "news", logging.Source, "newsapi")
 	return &Client{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		apiKey:     apiKey,
+		opts:       opts,
+		scorer:     NewScorer(DefaultSignals(opts.Scoring)...),
+		filter:     newFilter(opts.Filters, logger),
+		httpClient: httpClient,
+		quota:      quota,
+		logger:     logger,
 	}
 }
 
-func (c *Client) FetchLatestTechNews(language string) (*Article, error) {
+// SetPreferences задаёт предпочтения аудитории, которые учитываются при выборе статьи
+func (c *Client) SetPreferences(preferences *Preferences) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.preferences = preferences
+}
+
+func (c *Client) FetchLatestTechNews(ctx context.Context, language string) (*Article, error) {
 	// Получаем несколько статей для выбора лучшей
-	articles, err := c.fetchArticles(language, 10)
+	articles, err := c.fetchArticles(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+	articles = c.filterArticles(ctx, articles, language)
+
+	if len(articles) == 0 {
+		return nil, fmt.Errorf("no articles found")
+	}
+
+	// Выбираем статью с наибольшей оценкой
+	selectCtx, span := tracer.Start(ctx, "news.select", trace.WithAttributes(attribute.Int("news.candidates", len(articles))))
+	bestArticle := c.selectBestArticle(selectCtx, articles)
+	span.SetAttributes(attribute.String(logging.ArticleURL, bestArticle.URL))
+	span.End()
+
+	c.prepareArticle(ctx, bestArticle)
+	c.logger.InfoContext(ctx, "Article selected", logging.ArticleURL, bestArticle.URL, "candidates", len(articles), "score", bestArticle.Score)
+
+	return bestArticle, nil
+}
+
+// FetchCandidates возвращает до limit статей о разных историях, отсортированных по убыванию
+// оценки. Используется модерацией, чтобы редакторы могли перейти к следующему кандидату.
+func (c *Client) FetchCandidates(ctx context.Context, language string, limit int) ([]*Article, error) {
+	articles, err := c.fetchArticles(ctx, language)
 	if err != nil {
 		return nil, err
 	}
+	articles = c.filterArticles(ctx, articles, language)
 
 	if len(articles) == 0 {
 		return nil, fmt.Errorf("no articles found")
 	}
 
-	// Выбираем лучшую статью на основе длины контента и наличия важных полей
-	bestArticle := c.selectBestArticle(articles)
+	selectCtx, span := tracer.Start(ctx, "news.select", trace.WithAttributes(attribute.Int("news.candidates", len(articles))))
+	candidates := c.rankArticles(selectCtx, articles)
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	span.End()
+
+	for _, article := range candidates {
+		c.prepareArticle(ctx, article)
+	}
+
+	return candidates, nil
+}
+
+// prepareArticle приводит контент статьи к виду, пригодному для отправки
+func (c *Client) prepareArticle(ctx context.Context, article *Article) {
+	_, span := tracer.Start(ctx, "news.extract", trace.WithAttributes(attribute.String(logging.ArticleURL, article.URL)))
+	defer span.End()
 
 	// Дополняем контент описанием, если он короткий
-	if len(bestArticle.Content) < len(bestArticle.Description) {
-		bestArticle.Content = bestArticle.Description + "\n\n" + bestArticle.Content
+	if len(article.Content) < len(article.Description) {
+		article.Content = article.Description + "\n\n" + article.Content
 	}
 
 	// Очищаем контент от технических артефактов
-	bestArticle.Content = c.cleanContent(bestArticle.Content)
+	article.Content = c.cleanContent(article.Content)
+	span.SetAttributes(attribute.Int("news.content_length", len(article.Content)))
+}
 
-	return bestArticle, nil
+// fetchArticles запрашивает статьи по c.opts.Query, страницу за страницей. Ошибка на первой
+// странице возвращается; на следующих — попадает в журнал, и возвращаются уже полученные статьи.
+func (c *Client) fetchArticles(ctx context.Context, language string) ([]Article, error) {
+	baseURL := "https://" + APIHost + c.opts.Query.path()
+
+	c.logger.DebugContext(ctx, "Requesting articles", "endpoint", baseURL, "language", language,
+		"page_size", c.opts.Query.PageSize, "pages", c.opts.Query.Pages, "domains", len(c.opts.Query.Domains))
+	fetchCtx, span := tracer.Start(ctx, "news.fetch", trace.WithAttributes(
+		attribute.String("news.endpoint", baseURL),
+		attribute.String("news.language", language),
+		attribute.Int("news.domains", len(c.opts.Query.Domains)),
+	))
+	articles, err := c.fetchPages(fetchCtx, baseURL, c.opts.Query, language)
+	span.SetAttributes(attribute.Int("news.articles", len(articles)))
+	tracing.End(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.InfoContext(ctx, "Articles fetched", "count", len(articles))
+
+	if len(articles) == 0 && c.opts.Query.Endpoint == EndpointEverything && len(c.opts.Query.Domains) > 0 && c.opts.Query.Q != "" {
+		// Если статьи не найдены, пробуем более широкий поиск без ограничения по доменам
+		query := c.opts.Query
+		query.Domains = nil
+
+		c.logger.DebugContext(ctx, "Requesting articles without domain filter", "endpoint", baseURL, "language", language, "page_size", query.PageSize)
+		fetchCtx, span := tracer.Start(ctx, "news.fetch_fallback", trace.WithAttributes(
+			attribute.String("news.endpoint", baseURL),
+			attribute.String("news.language", language),
+		))
+		articles, err = c.fetchPages(fetchCtx, baseURL, query, language)
+		span.SetAttributes(attribute.Int("news.articles", len(articles)))
+		tracing.End(span, err)
+		if err != nil {
+			return nil, fmt.Errorf("fallback request: %w", err)
+		}
+
+		c.logger.InfoContext(ctx, "Articles fetched in fallback request", "count", len(articles))
+	}
+
+	if c.quota != nil && c.quota.Limit() > 0 {
+		c.logger.InfoContext(ctx, "NewsAPI quota", "remaining", c.quota.Remaining(), "limit", c.quota.Limit())
+	}
+
+	return articles, nil
 }
 
-func (c *Client) fetchArticles(language string, pageSize int) ([]Article, error) {
-	// Используем everything endpoint вместо top-headlines для большего охвата
-	baseURL := "https://newsapi.org/v2/everything"
-	
-	params := url.Values{}
-	params.Add("language", language)
-	params.Add("pageSize", fmt.Sprintf("%d", pageSize))
-	params.Add("sortBy", "publishedAt")
-
-	// Добавляем основные технологические домены
-	domains := []string{
-		"techcrunch.com",
-		"theverge.com",
-		"wired.com",
-		"arstechnica.com",
-		"engadget.com",
-		"zdnet.com",
-		"venturebeat.com",
-		"thenextweb.com",
-	}
-	params.Add("domains", strings.Join(domains, ","))
-	
-	// Используем только базовый поиск для начала
-	params.Add("q", "technology")
+// fetchPages запрашивает до query.Pages страниц и останавливается, когда статьи закончились.
+// Статьи, попавшие на две страницы из-за новых публикаций, не повторяются.
+func (c *Client) fetchPages(ctx context.Context, baseURL string, query Query, language string) ([]Article, error) {
+	var articles []Article
+	seen := make(map[string]bool)
+	now := time.Now()
+
+	for page := 1; page <= query.Pages; page++ {
+		resp, err := c.requestArticles(ctx, baseURL+"?"+query.params(language, page, now).Encode())
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			c.logger.WarnContext(ctx, "Error requesting next page, using articles already fetched", "page", page, "count", len(articles), logging.Error, err)
+			break
+		}
 
-	requestURL := baseURL + "?" + params.Encode()
-	fmt.Printf("Making request to: %s\n", requestURL)
+		for _, article := range resp.Articles {
+			if !seen[article.URL] {
+				seen[article.URL] = true
+				articles = append(articles, article)
+			}
+		}
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+		if len(resp.Articles) < query.PageSize || page*query.PageSize >= resp.TotalResults {
+			break
+		}
+	}
+
+	return articles, nil
+}
+
+// requestArticles выполняет один запрос к NewsAPI и возвращает его ответ
+func (c *Client) requestArticles(ctx context.Context, requestURL string) (*NewsAPIResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	req.Header.Set("X-Api-Key", c.apiKey)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
@@ -118,92 +309,93 @@ func (c *Client) fetchArticles(language string, pageSize int) ([]Article, error)
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	fmt.Printf("Found %d articles\n", len(apiResp.Articles))
-
-	if len(apiResp.Articles) == 0 {
-		// Если статьи не найдены, пробуем более широкий поиск
-		params.Del("domains") // Убираем ограничение по доменам
-		
-		requestURL = baseURL + "?" + params.Encode()
-		fmt.Printf("Making fallback request to: %s\n", requestURL)
-
-		req, err = http.NewRequest("GET", requestURL, nil)
-		if err != nil {
-			return nil, fmt.Errorf("error creating fallback request: %w", err)
-		}
-
-		req.Header.Set("X-Api-Key", c.apiKey)
-
-		resp, err = c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error making fallback request: %w", err)
-		}
-		defer resp.Body.Close()
+	return &apiResp, nil
+}
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("news API returned non-200 status code in fallback: %d, body: %s", resp.StatusCode, string(body))
-		}
+// do выполняет запрос к NewsAPI и записывает его длительность и код ответа в метрики.
+// Ответы из кэша и запросы, отклонённые бюджетом или автоматом защиты, в метрики не попадают:
+// до NewsAPI они не доходили.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if errors.Is(err, httpclient.ErrQuotaExceeded) || errors.Is(err, httpclient.ErrCircuitOpen) {
+		return nil, err
+	}
 
-		if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-			return nil, fmt.Errorf("error decoding fallback response: %w", err)
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+		cache := resp.Header.Get(httpclient.CacheStatusHeader)
+		trace.SpanFromContext(req.Context()).SetAttributes(
+			attribute.Int("http.response.status_code", status),
+			attribute.String("http.cache", cache),
+		)
+		if cache == httpclient.CacheHit {
+			c.logger.DebugContext(req.Context(), "Articles taken from cache")
+			return resp, nil
 		}
-		
-		fmt.Printf("Found %d articles in fallback request\n", len(apiResp.Articles))
 	}
+	metrics.ObserveNewsAPI(start, status)
 
-	return apiResp.Articles, nil
+	return resp, err
 }
 
-func (c *Client) selectBestArticle(articles []Article) *Article {
-	var bestArticle *Article
-	var maxScore int
-
-	for i, article := range articles {
-		score := 0
-		
-		// Оценка длины контента
-		score += len(article.Content) / 10
-		score += len(article.Description) / 10
-		
-		// Бонус за наличие автора
-		if article.Author != "" {
-			score += 50
-		}
-
-		// Бонус за наличие источника
-		if article.Source.Name != "" {
-			score += 30
-		}
+func (c *Client) selectBestArticle(ctx context.Context, articles []Article) *Article {
+	return c.rankArticles(ctx, articles)[0]
+}
 
-		// Бонус за свежесть новости
-		hoursAgo := time.Since(article.PublishedAt).Hours()
-		if hoursAgo < 24 {
-			score += 100
-		} else if hoursAgo < 48 {
-			score += 50
-		}
+// rankArticles объединяет статьи об одной истории в кластеры и возвращает лучшую статью
+// каждого кластера, отсортированные по убыванию оценки. Число других изданий, написавших
+// об истории, — один из признаков оценки: широкое освещение говорит о её важности.
+// Порядок лучших историй затем уточняет Ranker, если он задан.
+func (c *Client) rankArticles(ctx context.Context, articles []Article) []*Article {
+	all := make([]*Article, len(articles))
+	for i := range articles {
+		all[i] = &articles[i]
+	}
 
-		// Бонус за технологические ключевые слова в заголовке и описании
-		techKeywords := []string{"technology", "tech", "software", "AI", "artificial intelligence", 
-			"cybersecurity", "digital", "innovation", "startup", "algorithm", "cloud", "data", 
-			"security", "privacy", "blockchain", "machine learning"}
-		
-		combinedText := strings.ToLower(article.Title + " " + article.Description)
-		for _, keyword := range techKeywords {
-			if strings.Contains(combinedText, strings.ToLower(keyword)) {
-				score += 20
+	clusters := clusterArticles(all, c.opts.ClusterThreshold)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("news.clusters", len(clusters)))
+
+	c.mu.RLock()
+	preferences := c.preferences
+	c.mu.RUnlock()
+	now := time.Now()
+
+	ranked := make([]*Article, 0, len(clusters))
+	for _, cluster := range clusters {
+		sources := cluster.Sources()
+		for _, article := range cluster.Articles {
+			article.Coverage = nil
+			for _, source := range sources {
+				if source != sourceName(article) {
+					article.Coverage = append(article.Coverage, source)
+				}
 			}
+			article.Score = c.scorer.Score(&ScoreInput{Article: article, Preferences: preferences, Now: now})
+			c.logger.DebugContext(ctx, "Article scored", logging.ArticleURL, article.URL, "score", article.Score)
 		}
 
-		// Выбираем статью с наивысшим счётом
-		if bestArticle == nil || score > maxScore {
-			bestArticle = &articles[i]
-			maxScore = score
+		sortByScore(cluster.Articles)
+		best := cluster.Articles[0]
+		if len(cluster.Articles) > 1 {
+			c.logger.DebugContext(ctx, "Story covered by several articles", logging.ArticleURL, best.URL,
+				"articles", len(cluster.Articles), "coverage", strings.Join(best.Coverage, ", "))
 		}
+		ranked = append(ranked, best)
 	}
 
-	return bestArticle
+	sortByScore(ranked)
+	c.rerank(ctx, ranked)
+
+	return ranked
+}
+
+// sortByScore упорядочивает статьи по убыванию оценки
+func sortByScore(articles []*Article) {
+	sort.SliceStable(articles, func(i, j int) bool {
+		return articles[i].Score.Total > articles[j].Score.Total
+	})
 }
 
 func (c *Client) cleanContent(content string) string {
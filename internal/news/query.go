@@ -0,0 +1,88 @@
+package news
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Методы NewsAPI, из которых берутся статьи
+const (
+	// EndpointEverything ищет по всем статьям: поддерживает домены, язык, сортировку и период
+	EndpointEverything = "everything"
+	// EndpointTopHeadlines возвращает главные новости по категории и стране
+	EndpointTopHeadlines = "top-headlines"
+)
+
+// Query — параметры запроса статей к NewsAPI. Domains, ExcludeDomains, SortBy,
+// From и To передаются только методу everything, Category и Country — только top-headlines.
+type Query struct {
+	Endpoint string
+	// Q — поисковый запрос; пустое значение — без поиска по словам
+	Q              string
+	Domains        []string
+	ExcludeDomains []string
+	// SortBy — relevancy, popularity или publishedAt
+	SortBy   string
+	Category string
+	// Country — двухбуквенный код страны; пустое значение — все страны
+	Country string
+	// PageSize — статей на странице, Pages — сколько страниц запрашивать за запуск
+	PageSize int
+	Pages    int
+	// From и To задают период публикации относительно момента запроса:
+	// From: 48h — не старше двух суток. 0 — период не ограничен с этой стороны.
+	From time.Duration
+	To   time.Duration
+}
+
+// path возвращает путь метода NewsAPI
+func (q Query) path() string {
+	return "/v2/" + q.Endpoint
+}
+
+// params возвращает параметры запроса страницы page. Границы периода округляются
+// вниз до часа, чтобы повторные запросы в течение часа совпадали и брались из кэша.
+func (q Query) params(language string, page int, now time.Time) url.Values {
+	params := url.Values{}
+	params.Set("pageSize", strconv.Itoa(q.PageSize))
+	params.Set("page", strconv.Itoa(page))
+	if q.Q != "" {
+		params.Set("q", q.Q)
+	}
+
+	if q.Endpoint == EndpointTopHeadlines {
+		if q.Category != "" {
+			params.Set("category", q.Category)
+		}
+		if q.Country != "" {
+			params.Set("country", q.Country)
+		}
+		return params
+	}
+
+	params.Set("language", language)
+	if q.SortBy != "" {
+		params.Set("sortBy", q.SortBy)
+	}
+	if len(q.Domains) > 0 {
+		params.Set("domains", strings.Join(q.Domains, ","))
+	}
+	if len(q.ExcludeDomains) > 0 {
+		params.Set("excludeDomains", strings.Join(q.ExcludeDomains, ","))
+	}
+
+	now = now.UTC().Truncate(time.Hour)
+	if q.From > 0 {
+		params.Set("from", now.Add(-q.From).Format(timeLayout))
+	}
+	if q.To > 0 {
+		params.Set("to", now.Add(-q.To).Format(timeLayout))
+	}
+
+	return params
+}
+
+// timeLayout — формат даты ISO 8601, который принимает NewsAPI
+const timeLayout = "2006-01-02T15:04:05"
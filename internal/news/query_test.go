@@ -0,0 +1,95 @@
+package news
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestQueryParams(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 34, 56, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query Query
+		page  int
+		want  url.Values
+	}{
+		{
+			name: "everything with all options",
+			query: Query{
+				Endpoint:       EndpointEverything,
+				Q:              "technology",
+				Domains:        []string{"techcrunch.com", "wired.com"},
+				ExcludeDomains: []string{"example.com"},
+				SortBy:         "publishedAt",
+				Category:       "technology",
+				Country:        "us",
+				PageSize:       20,
+				From:           48 * time.Hour,
+				To:             time.Hour,
+			},
+			page: 2,
+			want: url.Values{
+				"pageSize":       {"20"},
+				"page":           {"2"},
+				"q":              {"technology"},
+				"language":       {"en"},
+				"sortBy":         {"publishedAt"},
+				"domains":        {"techcrunch.com,wired.com"},
+				"excludeDomains": {"example.com"},
+				"from":           {"2025-02-27T12:00:00"},
+				"to":             {"2025-03-01T11:00:00"},
+			},
+		},
+		{
+			name:  "everything without optional parameters",
+			query: Query{Endpoint: EndpointEverything, Q: "golang", PageSize: 10},
+			page:  1,
+			want: url.Values{
+				"pageSize": {"10"},
+				"page":     {"1"},
+				"q":        {"golang"},
+				"language": {"en"},
+			},
+		},
+		{
+			name: "top-headlines ignores everything parameters",
+			query: Query{
+				Endpoint: EndpointTopHeadlines,
+				Domains:  []string{"techcrunch.com"},
+				SortBy:   "publishedAt",
+				Category: "technology",
+				Country:  "us",
+				PageSize: 10,
+				From:     48 * time.Hour,
+			},
+			page: 1,
+			want: url.Values{
+				"pageSize": {"10"},
+				"page":     {"1"},
+				"category": {"technology"},
+				"country":  {"us"},
+			},
+		},
+		{
+			name:  "top-headlines without category and country",
+			query: Query{Endpoint: EndpointTopHeadlines, Q: "apple", PageSize: 10},
+			page:  1,
+			want: url.Values{
+				"pageSize": {"10"},
+				"page":     {"1"},
+				"q":        {"apple"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.query.params("en", tt.page, now)
+			if got.Encode() != tt.want.Encode() {
+				t.Errorf("params() = %s, want %s", got.Encode(), tt.want.Encode())
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package news
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SignalRelevance — признак оценки, который выставляет Ranker
+const SignalRelevance = "relevance"
+
+// Ranker оценивает техническую глубину и интерес статей для аудитории, например
+// с помощью языковой модели. Все статьи оцениваются одним запросом.
+type Ranker interface {
+	// Rank возвращает оценки в порядке статей; nil — статья осталась без оценки
+	Rank(ctx context.Context, articles []*Article) ([]*Relevance, error)
+}
+
+// Relevance — оценка статьи от 0 до 1 с коротким пояснением
+type Relevance struct {
+	Score  float64
+	Reason string
+}
+
+// RankingOptions — настройки оценки статей через Ranker
+type RankingOptions struct {
+	// Shortlist — сколько лучших по эвристике историй оценивается; 0 отключает оценку
+	Shortlist int
+	// Weight — вес признака relevance
+	Weight float64
+}
+
+// SetRanker задаёт Ranker, которым уточняется порядок лучших статей; nil отключает оценку
+func (c *Client) SetRanker(ranker Ranker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ranker = ranker
+}
+
+// rerank добавляет к оценке первых opts.Ranking.Shortlist статей признак relevance
+// и переупорядочивает их. Статьи за пределами списка остаются ниже в прежнем порядке:
+// их Ranker не видел, и сравнивать их оценки с оценками списка нельзя.
+// При ошибке Ranker порядок не меняется.
+func (c *Client) rerank(ctx context.Context, ranked []*Article) {
+	c.mu.RLock()
+	ranker := c.ranker
+	c.mu.RUnlock()
+
+	opts := c.opts.Ranking
+	if ranker == nil || opts.Shortlist <= 0 || opts.Weight == 0 || len(ranked) < 2 {
+		return
+	}
+
+	shortlist := ranked[:min(opts.Shortlist, len(ranked))]
+	ctx, span := tracer.Start(ctx, "news.rank", trace.WithAttributes(attribute.Int("news.shortlist", len(shortlist))))
+	err := c.applyRelevance(ctx, ranker, shortlist)
+	tracing.End(span, err)
+	if err != nil {
+		c.logger.WarnContext(ctx, "Error ranking articles, keeping heuristic order", "shortlist", len(shortlist), logging.Error, err)
+		return
+	}
+
+	sortByScore(shortlist)
+}
+
+func (c *Client) applyRelevance(ctx context.Context, ranker Ranker, shortlist []*Article) error {
+	relevance, err := ranker.Rank(ctx, shortlist)
+	if err != nil {
+		return err
+	}
+	if len(relevance) != len(shortlist) {
+		return fmt.Errorf("ranker returned %d scores for %d articles", len(relevance), len(shortlist))
+	}
+
+	for i, article := range shortlist {
+		r := relevance[i]
+		if r == nil {
+			c.logger.DebugContext(ctx, "Article was not ranked", logging.ArticleURL, article.URL)
+			continue
+		}
+
+		// 0.5 — нейтральная оценка, выше поднимает статью, ниже опускает
+		value := max(-1, min(1, 2*r.Score-1))
+		article.Score.Signals = append(article.Score.Signals, SignalScore{
+			Name:   SignalRelevance,
+			Value:  value,
+			Weight: c.opts.Ranking.Weight,
+			Note:   r.Reason,
+		})
+		article.Score.Total += value * c.opts.Ranking.Weight
+		c.logger.DebugContext(ctx, "Article ranked", logging.ArticleURL, article.URL, "relevance", r.Score, "reason", r.Reason)
+	}
+
+	return nil
+}
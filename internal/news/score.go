@@ -0,0 +1,326 @@
+package news
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Имена признаков оценки статьи: по ним задаются веса и выводится разбор оценки
+const (
+	SignalFreshness    = "freshness"
+	SignalCompleteness = "completeness"
+	SignalSource       = "source"
+	SignalTopics       = "topics"
+	SignalCoverage     = "coverage"
+	SignalFeedback     = "feedback"
+	SignalBlocklist    = "blocklist"
+)
+
+// ScoreInput — данные, по которым признаки оценивают статью
+type ScoreInput struct {
+	Article *Article
+	// Preferences — предпочтения аудитории; nil, если отзывов ещё нет
+	Preferences *Preferences
+	Now         time.Time
+}
+
+// Signal — признак оценки статьи. Value возвращает значение от -1 до 1, которое
+// умножается на вес признака.
+type Signal interface {
+	Name() string
+	Value(in *ScoreInput) float64
+}
+
+// WeightedSignal — признак вместе с весом
+type WeightedSignal struct {
+	Signal Signal
+	Weight float64
+}
+
+// Scorer оценивает статью как взвешенную сумму признаков
+type Scorer struct {
+	signals []WeightedSignal
+}
+
+// NewScorer создаёт оценщик из признаков; признаки с нулевым весом не учитываются
+func NewScorer(signals ...WeightedSignal) *Scorer {
+	s := &Scorer{}
+	for _, signal := range signals {
+		if signal.Weight != 0 {
+			s.signals = append(s.signals, signal)
+		}
+	}
+
+	return s
+}
+
+// Score возвращает оценку статьи с разбором по признакам
+func (s *Scorer) Score(in *ScoreInput) *Score {
+	score := &Score{}
+	for _, signal := range s.signals {
+		value := max(-1, min(1, signal.Signal.Value(in)))
+		score.Signals = append(score.Signals, SignalScore{Name: signal.Signal.Name(), Value: value, Weight: signal.Weight})
+		score.Total += value * signal.Weight
+	}
+
+	return score
+}
+
+// Score — оценка статьи и вклад каждого признака в неё
+type Score struct {
+	Total   float64
+	Signals []SignalScore
+}
+
+// SignalScore — значение признака и его вес
+type SignalScore struct {
+	Name   string
+	Value  float64
+	Weight float64
+	// Note — пояснение значения, если признак его даёт
+	Note string
+}
+
+// Points возвращает вклад признака в оценку
+func (s SignalScore) Points() float64 {
+	return s.Value * s.Weight
+}
+
+// String возвращает оценку в виде «151.0 = freshness 92.0 + coverage 40.0 - blocklist 200.0»
+func (s *Score) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%.1f", s.Total)
+	first := true
+	for _, signal := range s.Signals {
+		points := signal.Points()
+		if points == 0 {
+			continue
+		}
+
+		switch {
+		case first && points < 0:
+			b.WriteString(" = -")
+		case first:
+			b.WriteString(" = ")
+		case points < 0:
+			b.WriteString(" - ")
+		default:
+			b.WriteString(" + ")
+		}
+		fmt.Fprintf(&b, "%s %.1f", signal.Name, math.Abs(points))
+		first = false
+	}
+
+	return b.String()
+}
+
+// LogValue выводит оценку в журнал группой: total и вклад каждого признака
+func (s *Score) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.Float64("total", round(s.Total))}
+	for _, signal := range s.Signals {
+		attrs = append(attrs, slog.Float64(signal.Name, round(signal.Points())))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// ScoringOptions — настройки признаков оценки
+type ScoringOptions struct {
+	// Weights — веса признаков по именам Signal*; отсутствующий признак не учитывается
+	Weights map[string]float64
+	// FreshnessHalfLife — возраст, в котором признак свежести падает вдвое
+	FreshnessHalfLife time.Duration
+	// Sources — репутация изданий от -1 до 1 по названию или домену
+	Sources map[string]float64
+	// Keywords — темы, совпадения с которыми поднимают оценку
+	Keywords []string
+	// Blocklist — слова, статьи с которыми опускаются вниз
+	Blocklist []string
+}
+
+// DefaultSignals возвращает встроенные признаки с весами из opts
+func DefaultSignals(opts ScoringOptions) []WeightedSignal {
+	signals := []Signal{
+		freshnessSignal{halfLife: opts.FreshnessHalfLife},
+		completenessSignal{},
+		sourceSignal{reputation: lowerKeys(opts.Sources)},
+		topicsSignal{keywords: opts.Keywords},
+		coverageSignal{},
+		feedbackSignal{},
+		blocklistSignal{terms: opts.Blocklist},
+	}
+
+	weighted := make([]WeightedSignal, 0, len(signals))
+	for _, signal := range signals {
+		weighted = append(weighted, WeightedSignal{Signal: signal, Weight: opts.Weights[signal.Name()]})
+	}
+
+	return weighted
+}
+
+// freshnessSignal убывает вдвое с каждым halfLife возраста статьи
+type freshnessSignal struct {
+	halfLife time.Duration
+}
+
+func (freshnessSignal) Name() string { return SignalFreshness }
+
+func (s freshnessSignal) Value(in *ScoreInput) float64 {
+	if in.Article.PublishedAt.IsZero() || s.halfLife <= 0 {
+		return 0
+	}
+
+	age := max(in.Now.Sub(in.Article.PublishedAt), 0)
+	return math.Pow(0.5, float64(age)/float64(s.halfLife))
+}
+
+// completenessSignal оценивает наличие автора, описания и текста. Длина текста сверх
+// нескольких абзацев не учитывается: длинная статья не значит хорошая.
+type completenessSignal struct{}
+
+func (completenessSignal) Name() string { return SignalCompleteness }
+
+func (completenessSignal) Value(in *ScoreInput) float64 {
+	value := 0.0
+	if in.Article.Author != "" {
+		value += 0.2
+	}
+	if in.Article.Source.Name != "" {
+		value += 0.1
+	}
+	if len(in.Article.Description) >= 80 {
+		value += 0.3
+	}
+	value += 0.4 * min(float64(len(in.Article.Content))/minContentLength, 1)
+
+	return value
+}
+
+// minContentLength — длина текста, которой достаточно для сводки
+const minContentLength = 200
+
+// sourceSignal — репутация издания, заданная в настройках
+type sourceSignal struct {
+	reputation map[string]float64
+}
+
+func (sourceSignal) Name() string { return SignalSource }
+
+func (s sourceSignal) Value(in *ScoreInput) float64 {
+	if value, ok := s.reputation[strings.ToLower(in.Article.Source.Name)]; ok {
+		return value
+	}
+
+	// Домен сравнивается вместе с родительскими: blog.example.com подходит под example.com
+	u, err := url.Parse(in.Article.URL)
+	if err != nil {
+		return 0
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	for host != "" {
+		if value, ok := s.reputation[host]; ok {
+			return value
+		}
+		_, host, _ = strings.Cut(host, ".")
+	}
+
+	return 0
+}
+
+// topicsSignal растёт с числом тем в заголовке и описании: одна тема — 0.5, две — 0.75
+type topicsSignal struct {
+	keywords []string
+}
+
+func (topicsSignal) Name() string { return SignalTopics }
+
+func (s topicsSignal) Value(in *ScoreInput) float64 {
+	text := strings.ToLower(in.Article.Title + " " + in.Article.Description)
+	matches := 0
+	for _, keyword := range s.keywords {
+		if strings.Contains(text, strings.ToLower(keyword)) {
+			matches++
+		}
+	}
+
+	return 1 - math.Pow(0.5, float64(matches))
+}
+
+// coverageSignal растёт с числом других изданий, написавших о той же истории
+type coverageSignal struct{}
+
+func (coverageSignal) Name() string { return SignalCoverage }
+
+func (coverageSignal) Value(in *ScoreInput) float64 {
+	return 1 - math.Pow(0.5, float64(len(in.Article.Coverage)))
+}
+
+// Вклад отзывов об источнике, теме и ключевом термине в признак feedback
+const (
+	sourcePreferenceWeight  = 1
+	topicPreferenceWeight   = 0.5
+	keywordPreferenceWeight = 0.25
+)
+
+// feedbackSignal оценивает статью по отзывам подписчиков об источнике, темах и ключевых терминах
+type feedbackSignal struct{}
+
+func (feedbackSignal) Name() string { return SignalFeedback }
+
+func (feedbackSignal) Value(in *ScoreInput) float64 {
+	preferences := in.Preferences
+	if preferences == nil {
+		return 0
+	}
+
+	article := in.Article
+	value := sourcePreferenceWeight * preferences.Sources[article.Source.Name]
+
+	for _, topic := range article.Topics() {
+		value += topicPreferenceWeight * preferences.Topics[topic]
+	}
+
+	combinedText := strings.ToLower(article.Title + " " + article.Description)
+	for keyword, preference := range preferences.Keywords {
+		if strings.Contains(combinedText, strings.ToLower(keyword)) {
+			value += keywordPreferenceWeight * preference
+		}
+	}
+
+	return value
+}
+
+// blocklistSignal равен -1, если в заголовке или описании есть слово из списка
+type blocklistSignal struct {
+	terms []string
+}
+
+func (blocklistSignal) Name() string { return SignalBlocklist }
+
+func (s blocklistSignal) Value(in *ScoreInput) float64 {
+	text := strings.ToLower(in.Article.Title + " " + in.Article.Description)
+	for _, term := range s.terms {
+		if strings.Contains(text, strings.ToLower(term)) {
+			return -1
+		}
+	}
+
+	return 0
+}
+
+func lowerKeys(m map[string]float64) map[string]float64 {
+	lower := make(map[string]float64, len(m))
+	for key, value := range m {
+		lower[strings.ToLower(key)] = value
+	}
+
+	return lower
+}
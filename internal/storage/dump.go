@@ -0,0 +1,355 @@
+package storage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DumpVersion — версия формата выгрузки
+const DumpVersion = 1
+
+// Dump — переносимая копия данных подписчиков: профили и настройки доставки,
+// журнал статей, из которого выводятся предпочтения аудитории, отзывы и личные словари
+type Dump struct {
+	Version    int                `json:"version"`
+	ExportedAt time.Time          `json:"exported_at"`
+	Users      []UserRecord       `json:"users"`
+	Articles   []ArticleRecord    `json:"articles"`
+	Feedback   []FeedbackRecord   `json:"feedback"`
+	Vocabulary []VocabularyRecord `json:"vocabulary"`
+}
+
+// newDump создаёт пустую выгрузку; пустые разделы в JSON записываются как [], а не null
+func newDump() *Dump {
+	return &Dump{
+		Version:    DumpVersion,
+		ExportedAt: time.Now().UTC(),
+		Users:      []UserRecord{},
+		Articles:   []ArticleRecord{},
+		Feedback:   []FeedbackRecord{},
+		Vocabulary: []VocabularyRecord{},
+	}
+}
+
+// UserRecord — подписчик или чат-получатель вместе с профилем и настройками доставки
+type UserRecord struct {
+	ChatID       int64     `json:"chat_id"`
+	ChatType     string    `json:"chat_type"`
+	Title        string    `json:"title,omitempty"`
+	ThreadID     int       `json:"thread_id,omitempty"`
+	Silent       bool      `json:"silent"`
+	Active       bool      `json:"active"`
+	Username     string    `json:"username,omitempty"`
+	FirstName    string    `json:"first_name,omitempty"`
+	LastName     string    `json:"last_name,omitempty"`
+	LanguageCode string    `json:"language_code,omitempty"`
+	StartPayload string    `json:"start_payload,omitempty"`
+	LastSeen     time.Time `json:"last_seen"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ArticleRecord — статья из журнала доставки
+type ArticleRecord struct {
+	ArticleID   string    `json:"article_id"`
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Source      string    `json:"source"`
+	Topics      string    `json:"topics"`
+	Keywords    string    `json:"keywords"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// FeedbackRecord — отзыв участника чата о статье
+type FeedbackRecord struct {
+	ChatID    int64     `json:"chat_id"`
+	UserID    int64     `json:"user_id"`
+	ArticleID string    `json:"article_id"`
+	Kind      string    `json:"kind"`
+	Value     int       `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VocabularyRecord — термин из истории или личного словаря подписчика
+type VocabularyRecord struct {
+	ChatID      int64     `json:"chat_id"`
+	Term        string    `json:"term"`
+	Translation string    `json:"translation"`
+	ArticleID   string    `json:"article_id,omitempty"`
+	Saved       bool      `json:"saved"`
+	Ease        float64   `json:"ease"`
+	Interval    int       `json:"interval_days"`
+	Repetitions int       `json:"repetitions"`
+	DueAt       time.Time `json:"due_at"`
+}
+
+// WriteJSON записывает выгрузку в формате JSON
+func (d *Dump) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
+
+// ReadDumpJSON читает выгрузку в формате JSON
+func ReadDumpJSON(r io.Reader) (*Dump, error) {
+	var dump Dump
+	if err := json.NewDecoder(r).Decode(&dump); err != nil {
+		return nil, fmt.Errorf("error decoding dump: %w", err)
+	}
+	if dump.Version != DumpVersion {
+		return nil, fmt.Errorf("unsupported dump version %d, expected %d", dump.Version, DumpVersion)
+	}
+
+	return &dump, nil
+}
+
+// csvTable описывает файл выгрузки в формате CSV: одна таблица — один файл
+type csvTable struct {
+	file   string
+	header []string
+	rows   func(d *Dump) [][]string
+	add    func(d *Dump, row []string) error
+}
+
+var csvTables = []csvTable{
+	{
+		file: "users.csv",
+		header: []string{"chat_id", "chat_type", "title", "thread_id", "silent", "active", "username",
+			"first_name", "last_name", "language_code", "start_payload", "last_seen", "created_at"},
+		rows: func(d *Dump) [][]string {
+			var rows [][]string
+			for _, u := range d.Users {
+				rows = append(rows, []string{
+					formatInt(u.ChatID), u.ChatType, u.Title, strconv.Itoa(u.ThreadID),
+					strconv.FormatBool(u.Silent), strconv.FormatBool(u.Active), u.Username,
+					u.FirstName, u.LastName, u.LanguageCode, u.StartPayload,
+					formatTime(u.LastSeen), formatTime(u.CreatedAt),
+				})
+			}
+			return rows
+		},
+		add: func(d *Dump, row []string) error {
+			var u UserRecord
+			p := csvParser{row: row}
+			u.ChatID = p.int64(0)
+			u.ChatType = row[1]
+			u.Title = row[2]
+			u.ThreadID = p.int(3)
+			u.Silent = p.bool(4)
+			u.Active = p.bool(5)
+			u.Username, u.FirstName, u.LastName, u.LanguageCode, u.StartPayload = row[6], row[7], row[8], row[9], row[10]
+			u.LastSeen = p.time(11)
+			u.CreatedAt = p.time(12)
+			d.Users = append(d.Users, u)
+			return p.err
+		},
+	},
+	{
+		file:   "articles.csv",
+		header: []string{"article_id", "url", "title", "source", "topics", "keywords", "delivered_at"},
+		rows: func(d *Dump) [][]string {
+			var rows [][]string
+			for _, a := range d.Articles {
+				rows = append(rows, []string{a.ArticleID, a.URL, a.Title, a.Source, a.Topics, a.Keywords, formatTime(a.DeliveredAt)})
+			}
+			return rows
+		},
+		add: func(d *Dump, row []string) error {
+			p := csvParser{row: row}
+			d.Articles = append(d.Articles, ArticleRecord{
+				ArticleID:   row[0],
+				URL:         row[1],
+				Title:       row[2],
+				Source:      row[3],
+				Topics:      row[4],
+				Keywords:    row[5],
+				DeliveredAt: p.time(6),
+			})
+			return p.err
+		},
+	},
+	{
+		file:   "feedback.csv",
+		header: []string{"chat_id", "user_id", "article_id", "kind", "value", "created_at"},
+		rows: func(d *Dump) [][]string {
+			var rows [][]string
+			for _, f := range d.Feedback {
+				rows = append(rows, []string{formatInt(f.ChatID), formatInt(f.UserID), f.ArticleID, f.Kind, strconv.Itoa(f.Value), formatTime(f.CreatedAt)})
+			}
+			return rows
+		},
+		add: func(d *Dump, row []string) error {
+			p := csvParser{row: row}
+			d.Feedback = append(d.Feedback, FeedbackRecord{
+				ChatID:    p.int64(0),
+				UserID:    p.int64(1),
+				ArticleID: row[2],
+				Kind:      row[3],
+				Value:     p.int(4),
+				CreatedAt: p.time(5),
+			})
+			return p.err
+		},
+	},
+	{
+		file:   "vocabulary.csv",
+		header: []string{"chat_id", "term", "translation", "article_id", "saved", "ease", "interval_days", "repetitions", "due_at"},
+		rows: func(d *Dump) [][]string {
+			var rows [][]string
+			for _, v := range d.Vocabulary {
+				rows = append(rows, []string{
+					formatInt(v.ChatID), v.Term, v.Translation, v.ArticleID, strconv.FormatBool(v.Saved),
+					strconv.FormatFloat(v.Ease, 'f', -1, 64), strconv.Itoa(v.Interval), strconv.Itoa(v.Repetitions),
+					formatTime(v.DueAt),
+				})
+			}
+			return rows
+		},
+		add: func(d *Dump, row []string) error {
+			p := csvParser{row: row}
+			d.Vocabulary = append(d.Vocabulary, VocabularyRecord{
+				ChatID:      p.int64(0),
+				Term:        row[1],
+				Translation: row[2],
+				ArticleID:   row[3],
+				Saved:       p.bool(4),
+				Ease:        p.float(5),
+				Interval:    p.int(6),
+				Repetitions: p.int(7),
+				DueAt:       p.time(8),
+			})
+			return p.err
+		},
+	},
+}
+
+// WriteCSV записывает выгрузку в каталог dir, по одному CSV-файлу на таблицу
+func (d *Dump) WriteCSV(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating export directory: %w", err)
+	}
+
+	for _, table := range csvTables {
+		if err := writeCSVFile(filepath.Join(dir, table.file), table.header, table.rows(d)); err != nil {
+			return fmt.Errorf("error writing %s: %w", table.file, err)
+		}
+	}
+
+	return nil
+}
+
+func writeCSVFile(path string, header []string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// ReadDumpCSV читает выгрузку из каталога с CSV-файлами; отсутствующие файлы пропускаются
+func ReadDumpCSV(dir string) (*Dump, error) {
+	dump := newDump()
+
+	for _, table := range csvTables {
+		path := filepath.Join(dir, table.file)
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		records, err := csv.NewReader(file).ReadAll()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", table.file, err)
+		}
+		if len(records) == 0 {
+			continue
+		}
+		if len(records[0]) != len(table.header) {
+			return nil, fmt.Errorf("unexpected columns in %s: got %d, expected %d", table.file, len(records[0]), len(table.header))
+		}
+
+		for i, row := range records[1:] {
+			if err := table.add(dump, row); err != nil {
+				return nil, fmt.Errorf("%s line %d: %w", table.file, i+2, err)
+			}
+		}
+	}
+
+	return dump, nil
+}
+
+// csvParser разбирает значения строки CSV, запоминая первую ошибку
+type csvParser struct {
+	row []string
+	err error
+}
+
+func (p *csvParser) int64(i int) int64 {
+	v, err := strconv.ParseInt(p.row[i], 10, 64)
+	p.fail(err)
+	return v
+}
+
+func (p *csvParser) int(i int) int {
+	v, err := strconv.Atoi(p.row[i])
+	p.fail(err)
+	return v
+}
+
+func (p *csvParser) float(i int) float64 {
+	v, err := strconv.ParseFloat(p.row[i], 64)
+	p.fail(err)
+	return v
+}
+
+func (p *csvParser) bool(i int) bool {
+	v, err := strconv.ParseBool(p.row[i])
+	p.fail(err)
+	return v
+}
+
+func (p *csvParser) time(i int) time.Time {
+	if p.row[i] == "" {
+		return time.Time{}
+	}
+	v, err := time.Parse(time.RFC3339, p.row[i])
+	p.fail(err)
+	return v
+}
+
+func (p *csvParser) fail(err error) {
+	if err != nil && p.err == nil {
+		p.err = err
+	}
+}
+
+func formatInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+// formatTime записывает время в RFC 3339; нулевое время — пустая строка
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,689 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrei/goBot/internal/news"
+)
+
+// MemoryStore хранит данные в памяти процесса. Если задан путь к снимку,
+// состояние загружается из JSON-файла при создании и сохраняется в него после каждого изменения.
+type MemoryStore struct {
+	mu           sync.Mutex
+	snapshotPath string
+	logger       *slog.Logger
+	data         memoryData
+}
+
+// memoryData — всё состояние хранилища; сериализуется в снимок целиком
+type memoryData struct {
+	Chats        map[int64]*memoryChat                  `json:"chats"`
+	Articles     map[string]*memoryArticle              `json:"articles"`
+	Feedback     map[string]*memoryFeedback             `json:"feedback"`
+	ArticleTerms map[string]map[string]string           `json:"article_terms"`
+	Cards        map[int64]*memoryCard                  `json:"cards"`
+	NextCardID   int64                                  `json:"next_card_id"`
+	QuizPolls    map[string]*memoryQuizPoll             `json:"quiz_polls"`
+	QuizAnswers  map[string]map[int64]*memoryQuizAnswer `json:"quiz_answers"`
+	AuditLog     []memoryAudit                          `json:"audit_log"`
+}
+
+type memoryChat struct {
+	Target
+	Username     string    `json:"username"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	LanguageCode string    `json:"language_code"`
+	StartPayload string    `json:"start_payload"`
+	LastSeen     time.Time `json:"last_seen"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// updateProfile переносит в чат сведения о подписчике; вызывается под блокировкой
+func (c *memoryChat) updateProfile(profile Profile) {
+	c.Username = profile.Username
+	c.FirstName = profile.FirstName
+	c.LastName = profile.LastName
+	c.LanguageCode = profile.LanguageCode
+	c.LastSeen = profile.LastSeen
+}
+
+// profileChanged сообщает, отличаются ли сведения о подписчике от сохранённых, не считая LastSeen
+func (c *memoryChat) profileChanged(profile Profile) bool {
+	return c.Username != profile.Username ||
+		c.FirstName != profile.FirstName ||
+		c.LastName != profile.LastName ||
+		c.LanguageCode != profile.LanguageCode
+}
+
+type memoryArticle struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Source      string    `json:"source"`
+	Topics      []string  `json:"topics"`
+	Keywords    []string  `json:"keywords"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+type memoryFeedback struct {
+	ChatID    int64     `json:"chat_id"`
+	UserID    int64     `json:"user_id"`
+	ArticleID string    `json:"article_id"`
+	Kind      string    `json:"kind"`
+	Value     int       `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type memoryCard struct {
+	Card
+	ArticleID string `json:"article_id"`
+	Saved     bool   `json:"saved"`
+}
+
+type memoryQuizPoll struct {
+	ChatID        int64  `json:"chat_id"`
+	ArticleID     string `json:"article_id"`
+	CorrectOption int    `json:"correct_option"`
+}
+
+type memoryQuizAnswer struct {
+	UserName string `json:"user_name"`
+	Correct  bool   `json:"correct"`
+}
+
+// NewMemoryStore создаёт хранилище в памяти; snapshotPath может быть пустым
+func NewMemoryStore(snapshotPath string, logger *slog.Logger) (*MemoryStore, error) {
+	m := &MemoryStore{
+		snapshotPath: snapshotPath,
+		logger:       logger.With("component", "storage", "backend", BackendMemory),
+		data: memoryData{
+			Chats:        make(map[int64]*memoryChat),
+			Articles:     make(map[string]*memoryArticle),
+			Feedback:     make(map[string]*memoryFeedback),
+			ArticleTerms: make(map[string]map[string]string),
+			Cards:        make(map[int64]*memoryCard),
+			QuizPolls:    make(map[string]*memoryQuizPoll),
+			QuizAnswers:  make(map[string]map[int64]*memoryQuizAnswer),
+		},
+	}
+
+	if snapshotPath == "" {
+		return m, nil
+	}
+
+	content, err := os.ReadFile(snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		m.logger.Info("Snapshot not found, starting with empty storage", "path", snapshotPath)
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading snapshot: %w", err)
+	}
+	if err := json.Unmarshal(content, &m.data); err != nil {
+		return nil, fmt.Errorf("error decoding snapshot %s: %w", snapshotPath, err)
+	}
+
+	m.logger.Info("Loaded snapshot", "chats", len(m.data.Chats), "path", snapshotPath)
+	return m, nil
+}
+
+// persist сохраняет снимок; вызывается под блокировкой после каждого изменения
+func (m *MemoryStore) persist() {
+	if m.snapshotPath == "" {
+		return
+	}
+	if err := m.writeSnapshot(); err != nil {
+		m.logger.Error("Error writing snapshot", "path", m.snapshotPath, "error", err)
+	}
+}
+
+// writeSnapshot записывает снимок через временный файл, чтобы не повредить предыдущий при сбое
+func (m *MemoryStore) writeSnapshot() error {
+	content, err := json.Marshal(&m.data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.snapshotPath), 0755); err != nil {
+		return err
+	}
+
+	tmp := m.snapshotPath + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, m.snapshotPath)
+}
+
+// Add добавляет пользователя в хранилище
+func (m *MemoryStore) Add(chatID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if chat, ok := m.data.Chats[chatID]; ok {
+		chat.Active = true
+	} else {
+		m.data.Chats[chatID] = &memoryChat{
+			Target:    Target{ChatID: chatID, Type: "private"},
+			Active:    true,
+			CreatedAt: time.Now(),
+		}
+	}
+	m.persist()
+}
+
+// Subscribe добавляет подписчика или возобновляет его подписку, сохраняя профиль.
+// Параметр ссылки /start запоминается только при первом переходе, чтобы не терять источник.
+func (m *MemoryStore) Subscribe(profile Profile) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	chat, ok := m.data.Chats[profile.ChatID]
+	if !ok {
+		chat = &memoryChat{
+			Target:    Target{ChatID: profile.ChatID},
+			CreatedAt: time.Now(),
+		}
+		m.data.Chats[profile.ChatID] = chat
+	}
+
+	chat.Type = profile.ChatType
+	chat.Active = true
+	if chat.StartPayload == "" {
+		chat.StartPayload = profile.StartPayload
+	}
+	chat.updateProfile(profile)
+	m.persist()
+}
+
+// Touch обновляет профиль и время последнего взаимодействия уже известного подписчика.
+// Touch вызывается на каждое сообщение, поэтому снимок переписывается только при
+// изменении профиля; новое время взаимодействия попадёт в снимок со следующим
+// изменением или при закрытии хранилища.
+func (m *MemoryStore) Touch(profile Profile) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if chat, ok := m.data.Chats[profile.ChatID]; ok {
+		changed := chat.profileChanged(profile)
+		chat.updateProfile(profile)
+		if changed {
+			m.persist()
+		}
+	}
+}
+
+// AddTarget регистрирует группу или канал как получателя рассылки
+func (m *MemoryStore) AddTarget(target Target) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if chat, ok := m.data.Chats[target.ChatID]; ok {
+		chat.Target = target
+		chat.Active = true
+	} else {
+		m.data.Chats[target.ChatID] = &memoryChat{
+			Target:    target,
+			Active:    true,
+			CreatedAt: time.Now(),
+		}
+	}
+	m.persist()
+}
+
+// Get возвращает настройки активного получателя
+func (m *MemoryStore) Get(chatID int64) (Target, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	chat, ok := m.data.Chats[chatID]
+	if !ok || !chat.Active {
+		return Target{}, false
+	}
+
+	return chat.Target, true
+}
+
+// Deactivate отключает доставку в чат, сохраняя его настройки
+func (m *MemoryStore) Deactivate(chatID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if chat, ok := m.data.Chats[chatID]; ok {
+		chat.Active = false
+		m.persist()
+	}
+}
+
+// GetAll возвращает идентификаторы всех активных чатов
+func (m *MemoryStore) GetAll() []int64 {
+	var users []int64
+	for _, target := range m.GetTargets() {
+		users = append(users, target.ChatID)
+	}
+
+	return users
+}
+
+// GetTargets возвращает всех активных получателей рассылки
+func (m *MemoryStore) GetTargets() []Target {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var targets []Target
+	for _, chat := range m.data.Chats {
+		if chat.Active {
+			targets = append(targets, chat.Target)
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].ChatID < targets[j].ChatID
+	})
+
+	return targets
+}
+
+// Count возвращает количество активных чатов
+func (m *MemoryStore) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, chat := range m.data.Chats {
+		if chat.Active {
+			count++
+		}
+	}
+
+	return count
+}
+
+// RecordDelivery добавляет статью в журнал доставленных
+func (m *MemoryStore) RecordDelivery(article *news.Article, keywords []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.Articles[article.ID()] = &memoryArticle{
+		URL:         article.URL,
+		Title:       article.Title,
+		Source:      article.Source.Name,
+		Topics:      article.Topics(),
+		Keywords:    keywords,
+		DeliveredAt: time.Now(),
+	}
+	m.persist()
+}
+
+// RecordFeedback сохраняет отзыв; повторное нажатие заменяет предыдущий отзыв того же вида
+func (m *MemoryStore) RecordFeedback(chatID, userID int64, articleID, kind string, value int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.Feedback[feedbackKey(chatID, userID, articleID, kind)] = &memoryFeedback{
+		ChatID:    chatID,
+		UserID:    userID,
+		ArticleID: articleID,
+		Kind:      kind,
+		Value:     value,
+		CreatedAt: time.Now(),
+	}
+	m.persist()
+
+	return nil
+}
+
+// feedbackKey — ключ отзыва, повторяющий первичный ключ таблицы feedback
+func feedbackKey(chatID, userID int64, articleID, kind string) string {
+	return fmt.Sprintf("%d:%d:%s:%s", chatID, userID, articleID, kind)
+}
+
+// Preferences агрегирует отзывы по источникам, темам и ключевым терминам статей
+func (m *MemoryStore) Preferences() *news.Preferences {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sources := newPreferenceAccumulator()
+	topics := newPreferenceAccumulator()
+	keywords := newPreferenceAccumulator()
+
+	for _, feedback := range m.data.Feedback {
+		article, ok := m.data.Articles[feedback.ArticleID]
+		if !ok {
+			continue
+		}
+
+		weight := feedbackWeight(feedback.Kind)
+
+		sources.add(article.Source, feedback.Value, weight)
+		for _, topic := range article.Topics {
+			topics.add(topic, feedback.Value, weight)
+		}
+		for _, keyword := range article.Keywords {
+			keywords.add(keyword, feedback.Value, weight)
+		}
+	}
+
+	return &news.Preferences{
+		Sources:  sources.result(),
+		Topics:   topics.result(),
+		Keywords: keywords.result(),
+	}
+}
+
+// findCard ищет карточку подписчика по термину; вызывается под блокировкой
+func (m *MemoryStore) findCard(chatID int64, term string) *memoryCard {
+	for _, card := range m.data.Cards {
+		if card.ChatID == chatID && card.Term == term {
+			return card
+		}
+	}
+
+	return nil
+}
+
+// addCard создаёт карточку; вызывается под блокировкой
+func (m *MemoryStore) addCard(chatID int64, term, translation, articleID string) *memoryCard {
+	m.data.NextCardID++
+	card := &memoryCard{
+		Card: Card{
+			ID:          m.data.NextCardID,
+			ChatID:      chatID,
+			Term:        term,
+			Translation: translation,
+			Ease:        2.5,
+			DueAt:       time.Unix(0, 0),
+		},
+		ArticleID: articleID,
+	}
+	m.data.Cards[card.ID] = card
+
+	return card
+}
+
+// RecordTerms сохраняет термины статьи и добавляет их в историю каждого получателя
+func (m *MemoryStore) RecordTerms(articleID string, translations map[string]string, chatIDs []int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(translations) == 0 {
+		return
+	}
+
+	terms, ok := m.data.ArticleTerms[articleID]
+	if !ok {
+		terms = make(map[string]string)
+		m.data.ArticleTerms[articleID] = terms
+	}
+
+	for term, translation := range translations {
+		if _, ok := terms[term]; !ok {
+			terms[term] = translation
+		}
+
+		for _, chatID := range chatIDs {
+			if m.findCard(chatID, term) == nil {
+				m.addCard(chatID, term, translation, articleID)
+			}
+		}
+	}
+	m.persist()
+}
+
+// SaveTerms добавляет термины статьи в словарь для повторения и возвращает их количество
+func (m *MemoryStore) SaveTerms(chatID int64, articleID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	saved := 0
+	for term, translation := range m.data.ArticleTerms[articleID] {
+		card := m.findCard(chatID, term)
+		if card == nil {
+			card = m.addCard(chatID, term, translation, articleID)
+		}
+		if !card.Saved {
+			card.Saved = true
+			card.DueAt = now
+		}
+		saved++
+	}
+	m.persist()
+
+	return saved, nil
+}
+
+// NextDueCard возвращает ближайшую карточку к повторению; nil, если повторять нечего
+func (m *MemoryStore) NextDueCard(chatID int64, now time.Time) (*Card, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var next *Card
+	for _, card := range m.data.Cards {
+		if card.ChatID != chatID || !card.Saved || card.DueAt.After(now) {
+			continue
+		}
+		if next == nil || card.DueAt.Before(next.DueAt) {
+			c := card.Card
+			next = &c
+		}
+	}
+
+	return next, nil
+}
+
+// Card возвращает карточку по идентификатору
+func (m *MemoryStore) Card(id int64) (*Card, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	card, ok := m.data.Cards[id]
+	if !ok {
+		return nil, fmt.Errorf("card %d not found", id)
+	}
+
+	c := card.Card
+	return &c, nil
+}
+
+// UpdateCard сохраняет новое состояние интервального повторения карточки
+func (m *MemoryStore) UpdateCard(card *Card) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.data.Cards[card.ID]
+	if !ok {
+		return fmt.Errorf("card %d not found", card.ID)
+	}
+
+	stored.Ease = card.Ease
+	stored.Interval = card.Interval
+	stored.Repetitions = card.Repetitions
+	stored.DueAt = card.DueAt
+	m.persist()
+
+	return nil
+}
+
+// VocabularyStats возвращает число сохранённых терминов, число терминов к повторению
+// и последние сохранённые термины
+func (m *MemoryStore) VocabularyStats(chatID int64, now time.Time, limit int) (saved, due int, recent []Card, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, card := range m.data.Cards {
+		if card.ChatID != chatID || !card.Saved {
+			continue
+		}
+		saved++
+		if !card.DueAt.After(now) {
+			due++
+		}
+		recent = append(recent, card.Card)
+	}
+
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].ID > recent[j].ID
+	})
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+
+	return saved, due, recent, nil
+}
+
+// ChatsWithDueCards возвращает активные чаты, в которых есть термины к повторению
+func (m *MemoryStore) ChatsWithDueCards(now time.Time) (map[int64]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	chats := make(map[int64]int)
+	for _, card := range m.data.Cards {
+		if !card.Saved || card.DueAt.After(now) {
+			continue
+		}
+		if chat, ok := m.data.Chats[card.ChatID]; ok && chat.Active {
+			chats[card.ChatID]++
+		}
+	}
+
+	return chats, nil
+}
+
+// RecordQuizPoll запоминает отправленную викторину и правильный ответ на неё
+func (m *MemoryStore) RecordQuizPoll(pollID string, chatID int64, articleID string, correctOption int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data.QuizPolls[pollID]; ok {
+		return
+	}
+
+	m.data.QuizPolls[pollID] = &memoryQuizPoll{
+		ChatID:        chatID,
+		ArticleID:     articleID,
+		CorrectOption: correctOption,
+	}
+	m.persist()
+}
+
+// RecordQuizAnswer сохраняет ответ участника; ответы на неизвестные опросы игнорируются
+func (m *MemoryStore) RecordQuizAnswer(pollID string, userID int64, userName string, option int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	poll, ok := m.data.QuizPolls[pollID]
+	if !ok {
+		return nil
+	}
+
+	answers, ok := m.data.QuizAnswers[pollID]
+	if !ok {
+		answers = make(map[int64]*memoryQuizAnswer)
+		m.data.QuizAnswers[pollID] = answers
+	}
+	if _, ok := answers[userID]; ok {
+		return nil
+	}
+
+	answers[userID] = &memoryQuizAnswer{
+		UserName: userName,
+		Correct:  option == poll.CorrectOption,
+	}
+	m.persist()
+
+	return nil
+}
+
+// scores подсчитывает результаты участников; chatID, равный 0, означает все чаты.
+// Вызывается под блокировкой.
+func (m *MemoryStore) scores(chatID int64) map[int64]*Score {
+	scores := make(map[int64]*Score)
+	for pollID, answers := range m.data.QuizAnswers {
+		poll, ok := m.data.QuizPolls[pollID]
+		if !ok || (chatID != 0 && poll.ChatID != chatID) {
+			continue
+		}
+
+		for userID, answer := range answers {
+			score, ok := scores[userID]
+			if !ok {
+				score = &Score{UserID: userID}
+				scores[userID] = score
+			}
+			if strings.Compare(answer.UserName, score.UserName) > 0 {
+				score.UserName = answer.UserName
+			}
+			if answer.Correct {
+				score.Correct++
+			}
+			score.Total++
+		}
+	}
+
+	return scores
+}
+
+// Leaderboard возвращает лучших участников; chatID, равный 0, означает все чаты
+func (m *MemoryStore) Leaderboard(chatID int64, limit int) ([]Score, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var leaders []Score
+	for _, score := range m.scores(chatID) {
+		leaders = append(leaders, *score)
+	}
+
+	sort.Slice(leaders, func(i, j int) bool {
+		if leaders[i].Correct != leaders[j].Correct {
+			return leaders[i].Correct > leaders[j].Correct
+		}
+		return leaders[i].Total < leaders[j].Total
+	})
+	if len(leaders) > limit {
+		leaders = leaders[:limit]
+	}
+
+	return leaders, nil
+}
+
+// UserScore возвращает результат одного участника; chatID, равный 0, означает все чаты
+func (m *MemoryStore) UserScore(chatID, userID int64) (Score, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if score, ok := m.scores(chatID)[userID]; ok {
+		return *score, nil
+	}
+
+	return Score{UserID: userID}, nil
+}
+
+// Ping всегда успешен: хранилище в памяти доступно, пока работает процесс
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close сохраняет итоговый снимок хранилища
+func (m *MemoryStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.snapshotPath == "" {
+		return nil
+	}
+
+	return m.writeSnapshot()
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// memoryAudit — запись журнала аудита хранилища в памяти
+type memoryAudit struct {
+	ChatID    int64     `json:"chat_id"`
+	ActorID   int64     `json:"actor_id"`
+	Action    string    `json:"action"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Export выгружает данные чата; chatID, равный 0, означает все чаты
+func (m *MemoryStore) Export(chatID int64) (*Dump, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dump := newDump()
+
+	for _, chat := range m.data.Chats {
+		if chatID != 0 && chat.ChatID != chatID {
+			continue
+		}
+		dump.Users = append(dump.Users, UserRecord{
+			ChatID:       chat.ChatID,
+			ChatType:     chat.Type,
+			Title:        chat.Title,
+			ThreadID:     chat.ThreadID,
+			Silent:       chat.Silent,
+			Active:       chat.Active,
+			Username:     chat.Username,
+			FirstName:    chat.FirstName,
+			LastName:     chat.LastName,
+			LanguageCode: chat.LanguageCode,
+			StartPayload: chat.StartPayload,
+			LastSeen:     chat.LastSeen,
+			CreatedAt:    chat.CreatedAt,
+		})
+	}
+	sort.Slice(dump.Users, func(i, j int) bool {
+		return dump.Users[i].ChatID < dump.Users[j].ChatID
+	})
+
+	articles := make(map[string]bool)
+	for _, feedback := range m.data.Feedback {
+		if chatID != 0 && feedback.ChatID != chatID && feedback.UserID != chatID {
+			continue
+		}
+		articles[feedback.ArticleID] = true
+		dump.Feedback = append(dump.Feedback, FeedbackRecord{
+			ChatID:    feedback.ChatID,
+			UserID:    feedback.UserID,
+			ArticleID: feedback.ArticleID,
+			Kind:      feedback.Kind,
+			Value:     feedback.Value,
+			CreatedAt: feedback.CreatedAt,
+		})
+	}
+	sort.Slice(dump.Feedback, func(i, j int) bool {
+		a, b := dump.Feedback[i], dump.Feedback[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return feedbackKey(a.ChatID, a.UserID, a.ArticleID, a.Kind) < feedbackKey(b.ChatID, b.UserID, b.ArticleID, b.Kind)
+	})
+
+	for id, article := range m.data.Articles {
+		if chatID != 0 && !articles[id] {
+			continue
+		}
+		dump.Articles = append(dump.Articles, ArticleRecord{
+			ArticleID:   id,
+			URL:         article.URL,
+			Title:       article.Title,
+			Source:      article.Source,
+			Topics:      strings.Join(article.Topics, ","),
+			Keywords:    strings.Join(article.Keywords, ","),
+			DeliveredAt: article.DeliveredAt,
+		})
+	}
+	sort.Slice(dump.Articles, func(i, j int) bool {
+		return dump.Articles[i].DeliveredAt.Before(dump.Articles[j].DeliveredAt)
+	})
+
+	var cards []*memoryCard
+	for _, card := range m.data.Cards {
+		if chatID == 0 || card.ChatID == chatID {
+			cards = append(cards, card)
+		}
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].ID < cards[j].ID
+	})
+	for _, card := range cards {
+		dueAt := card.DueAt
+		if dueAt.Unix() == 0 {
+			dueAt = time.Time{}
+		}
+		dump.Vocabulary = append(dump.Vocabulary, VocabularyRecord{
+			ChatID:      card.ChatID,
+			Term:        card.Term,
+			Translation: card.Translation,
+			ArticleID:   card.ArticleID,
+			Saved:       card.Saved,
+			Ease:        card.Ease,
+			Interval:    card.Interval,
+			Repetitions: card.Repetitions,
+			DueAt:       dueAt,
+		})
+	}
+
+	return dump, nil
+}
+
+// Import загружает выгрузку, обновляя уже существующие записи
+func (m *MemoryStore) Import(dump *Dump) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range dump.Users {
+		chat, ok := m.data.Chats[u.ChatID]
+		if !ok {
+			chat = &memoryChat{CreatedAt: u.CreatedAt}
+			if chat.CreatedAt.IsZero() {
+				chat.CreatedAt = time.Now()
+			}
+			m.data.Chats[u.ChatID] = chat
+		}
+
+		chat.Target = Target{
+			ChatID:   u.ChatID,
+			Type:     u.ChatType,
+			Title:    u.Title,
+			ThreadID: u.ThreadID,
+			Silent:   u.Silent,
+		}
+		chat.Active = u.Active
+		chat.Username = u.Username
+		chat.FirstName = u.FirstName
+		chat.LastName = u.LastName
+		chat.LanguageCode = u.LanguageCode
+		chat.StartPayload = u.StartPayload
+		chat.LastSeen = u.LastSeen
+	}
+
+	for _, a := range dump.Articles {
+		if _, ok := m.data.Articles[a.ArticleID]; ok {
+			continue
+		}
+		m.data.Articles[a.ArticleID] = &memoryArticle{
+			URL:         a.URL,
+			Title:       a.Title,
+			Source:      a.Source,
+			Topics:      splitList(a.Topics),
+			Keywords:    splitList(a.Keywords),
+			DeliveredAt: a.DeliveredAt,
+		}
+	}
+
+	for _, f := range dump.Feedback {
+		key := feedbackKey(f.ChatID, f.UserID, f.ArticleID, f.Kind)
+		m.data.Feedback[key] = &memoryFeedback{
+			ChatID:    f.ChatID,
+			UserID:    f.UserID,
+			ArticleID: f.ArticleID,
+			Kind:      f.Kind,
+			Value:     f.Value,
+			CreatedAt: f.CreatedAt,
+		}
+	}
+
+	for _, v := range dump.Vocabulary {
+		card := m.findCard(v.ChatID, v.Term)
+		if card == nil {
+			card = m.addCard(v.ChatID, v.Term, v.Translation, v.ArticleID)
+		}
+
+		card.Translation = v.Translation
+		card.ArticleID = v.ArticleID
+		card.Saved = v.Saved
+		card.Ease = v.Ease
+		card.Interval = v.Interval
+		card.Repetitions = v.Repetitions
+		card.DueAt = time.Unix(unixSeconds(v.DueAt), 0)
+	}
+
+	m.persist()
+	return nil
+}
+
+// Forget удаляет все данные чата и записывает удаление в журнал аудита
+func (m *MemoryStore) Forget(chatID, actorID int64) (Deleted, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := make(Deleted)
+
+	for pollID, answers := range m.data.QuizAnswers {
+		if poll, ok := m.data.QuizPolls[pollID]; ok && poll.ChatID == chatID {
+			deleted["quiz_answers"] += int64(len(answers))
+			delete(m.data.QuizAnswers, pollID)
+			continue
+		}
+		if _, ok := answers[chatID]; ok {
+			deleted["quiz_answers"]++
+			delete(answers, chatID)
+		}
+	}
+
+	for pollID, poll := range m.data.QuizPolls {
+		if poll.ChatID == chatID {
+			deleted["quiz_polls"]++
+			delete(m.data.QuizPolls, pollID)
+		}
+	}
+
+	for key, feedback := range m.data.Feedback {
+		if feedback.ChatID == chatID || feedback.UserID == chatID {
+			deleted["feedback"]++
+			delete(m.data.Feedback, key)
+		}
+	}
+
+	for id, card := range m.data.Cards {
+		if card.ChatID == chatID {
+			deleted["vocabulary"]++
+			delete(m.data.Cards, id)
+		}
+	}
+
+	deleted["users"] = 0
+	if _, ok := m.data.Chats[chatID]; ok {
+		deleted["users"] = 1
+		delete(m.data.Chats, chatID)
+	}
+
+	m.data.AuditLog = append(m.data.AuditLog, memoryAudit{
+		ChatID:    chatID,
+		ActorID:   actorID,
+		Action:    AuditForget,
+		Details:   deleted.String(),
+		CreatedAt: time.Now(),
+	})
+	m.persist()
+
+	m.logger.Info("Audit: chat data deleted", "chat_id", chatID, "actor_id", actorID, "deleted", deleted.String())
+	return deleted, nil
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTouch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	store, err := NewMemoryStore(path, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewMemoryStore: %v", err)
+	}
+
+	profile := Profile{ChatID: 1, Username: "gopher", FirstName: "Go", ChatType: "private", LastSeen: time.Unix(100, 0)}
+	store.Subscribe(profile)
+	before := readSnapshot(t, path)
+
+	// Только новое время взаимодействия: снимок не переписывается
+	profile.LastSeen = time.Unix(200, 0)
+	store.Touch(profile)
+	if got := readSnapshot(t, path); got != before {
+		t.Errorf("snapshot was rewritten although only LastSeen changed")
+	}
+
+	profile.Username = "gopher2"
+	store.Touch(profile)
+	if got := readSnapshot(t, path); !strings.Contains(got, `"username":"gopher2"`) {
+		t.Errorf("snapshot does not contain the changed username: %s", got)
+	}
+
+	// Close сохраняет время взаимодействия, не попавшее в снимок
+	profile.LastSeen = time.Unix(300, 0)
+	store.Touch(profile)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewMemoryStore(path, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewMemoryStore: %v", err)
+	}
+	chat := reopened.data.Chats[1]
+	if chat == nil {
+		t.Fatal("chat is missing after reopen")
+	}
+	if !chat.LastSeen.Equal(profile.LastSeen) {
+		t.Errorf("LastSeen after reopen = %v, want %v", chat.LastSeen, profile.LastSeen)
+	}
+}
+
+func readSnapshot(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading snapshot: %v", err)
+	}
+
+	return string(content)
+}
@@ -0,0 +1,378 @@
+package storage
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// Migration — одна версия схемы базы данных
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus описывает, применена ли миграция к базе
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator применяет встроенные в бинарник миграции по порядку версий
+// и хранит номера применённых версий в таблице schema_version
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []Migration
+}
+
+// dialect описывает различия баз данных, важные для мигратора
+type dialect struct {
+	// tableExists — запрос числа таблиц с указанным именем
+	tableExists string
+	// numbered — параметры запроса нумеруются ($1, $2), а не задаются знаком ?
+	numbered bool
+	// lock — запрос, сериализующий миграции нескольких экземпляров бота; пустой — без блокировки
+	lock string
+	// legacy — база могла быть создана версиями бота без миграций
+	legacy bool
+}
+
+var (
+	sqliteDialect = dialect{
+		tableExists: "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?",
+		legacy:      true,
+	}
+	postgresDialect = dialect{
+		tableExists: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?",
+		numbered:    true,
+		lock:        "SELECT pg_advisory_xact_lock(hashtext('goBot schema_version'))",
+	}
+)
+
+// bind подставляет в запрос параметры в формате диалекта
+func (d dialect) bind(query string) string {
+	if !d.numbered {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// NewSQLiteMigrator создаёт мигратор для базы SQLite
+func NewSQLiteMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(sqliteMigrations, "migrations/sqlite")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, dialect: sqliteDialect, migrations: migrations}, nil
+}
+
+// NewPostgresMigrator создаёт мигратор для базы PostgreSQL
+func NewPostgresMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(postgresMigrations, "migrations/postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, dialect: postgresDialect, migrations: migrations}, nil
+}
+
+// loadMigrations читает файлы вида 0001_name.sql и сортирует их по версии
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		rawVersion, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %s: expected <version>_<name>.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(rawVersion)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up применяет все ещё не применённые миграции и возвращает их список
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		ok, err := m.apply(migration)
+		if err != nil {
+			return done, fmt.Errorf("error applying migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if ok {
+			done = append(done, migration)
+		}
+	}
+
+	return done, nil
+}
+
+// Status возвращает все известные миграции с отметкой о применении. Схему он не
+// меняет: если таблицы schema_version ещё нет, все миграции считаются неприменёнными.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	exists, err := m.versionTableExists()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time)
+	if exists {
+		if applied, err = m.applied(); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// init создаёт таблицу schema_version и при необходимости отмечает схему,
+// созданную до появления миграций
+func (m *Migrator) init() error {
+	exists, err := m.versionTableExists()
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.lock(tx); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at BIGINT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_version table: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error creating schema_version table: %w", err)
+	}
+
+	if !m.dialect.legacy {
+		return nil
+	}
+	return m.adoptLegacySchema()
+}
+
+// versionTableExists сообщает, создана ли таблица schema_version
+func (m *Migrator) versionTableExists() (bool, error) {
+	var count int
+	err := m.db.QueryRow(m.dialect.bind(m.dialect.tableExists), "schema_version").Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("error checking schema_version table: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// lock не даёт нескольким экземплярам бота применять миграции одновременно;
+// блокировка снимается вместе с завершением транзакции
+func (m *Migrator) lock(tx *sql.Tx) error {
+	if m.dialect.lock == "" {
+		return nil
+	}
+
+	if _, err := tx.Exec(m.dialect.lock); err != nil {
+		return fmt.Errorf("error locking schema_version: %w", err)
+	}
+
+	return nil
+}
+
+// adoptLegacySchema отмечает миграции, изменения которых уже внесены в базу
+// предыдущими версиями бота, создававшими схему без миграций. Остальные миграции
+// идемпотентны (CREATE TABLE IF NOT EXISTS) и применяются как обычно.
+func (m *Migrator) adoptLegacySchema() error {
+	columns, err := m.tableColumns("users")
+	if err != nil {
+		return err
+	}
+
+	var legacy []int
+	if len(columns) > 0 {
+		legacy = append(legacy, 1)
+	}
+	if columns["chat_type"] {
+		legacy = append(legacy, 2)
+	}
+
+	for _, version := range legacy {
+		for _, migration := range m.migrations {
+			if migration.Version != version {
+				continue
+			}
+			_, err := m.db.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+				migration.Version, migration.Name, time.Now().Unix())
+			if err != nil {
+				return fmt.Errorf("error recording legacy migration %d: %w", version, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) tableColumns(table string) (map[string]bool, error) {
+	rows, err := m.db.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("error reading columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+
+	return columns, rows.Err()
+}
+
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	rows, err := m.db.Query("SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema_version: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = time.Unix(appliedAt, 0)
+	}
+
+	return applied, rows.Err()
+}
+
+// apply выполняет миграцию и записывает её версию в одной транзакции.
+// Если миграцию успел применить другой экземпляр бота, она пропускается и возвращается false.
+func (m *Migrator) apply(migration Migration) (bool, error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := m.lock(tx); err != nil {
+		return false, err
+	}
+
+	var done int
+	err = tx.QueryRow(m.dialect.bind("SELECT COUNT(*) FROM schema_version WHERE version = ?"), migration.Version).Scan(&done)
+	if err != nil {
+		return false, err
+	}
+	if done > 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(migration.SQL); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(m.dialect.bind("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)"),
+		migration.Version, migration.Name, time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,6 @@
+-- Подписчики бота
+CREATE TABLE IF NOT EXISTS users (
+	chat_id BIGINT PRIMARY KEY,
+	username TEXT,
+	created_at TIMESTAMPTZ DEFAULT now()
+);
@@ -0,0 +1,6 @@
+-- Группы и каналы как получатели рассылки со своими настройками
+ALTER TABLE users ADD COLUMN IF NOT EXISTS chat_type TEXT NOT NULL DEFAULT 'private';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS title TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS thread_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS silent BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,20 @@
+-- Журнал доставленных статей и отзывы подписчиков о них
+CREATE TABLE IF NOT EXISTS articles (
+	article_id TEXT PRIMARY KEY,
+	url TEXT NOT NULL,
+	title TEXT,
+	source TEXT,
+	topics TEXT,
+	keywords TEXT,
+	delivered_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS feedback (
+	chat_id BIGINT NOT NULL,
+	user_id BIGINT NOT NULL,
+	article_id TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	value INTEGER NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT now(),
+	PRIMARY KEY (chat_id, user_id, article_id, kind)
+);
@@ -0,0 +1,22 @@
+-- Термины статей и личные словари подписчиков для интервального повторения
+CREATE TABLE IF NOT EXISTS article_terms (
+	article_id TEXT NOT NULL,
+	term TEXT NOT NULL,
+	translation TEXT NOT NULL,
+	PRIMARY KEY (article_id, term)
+);
+
+CREATE TABLE IF NOT EXISTS vocabulary (
+	id BIGSERIAL PRIMARY KEY,
+	chat_id BIGINT NOT NULL,
+	term TEXT NOT NULL,
+	translation TEXT NOT NULL,
+	article_id TEXT,
+	saved BOOLEAN NOT NULL DEFAULT FALSE,
+	ease DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+	interval_days INTEGER NOT NULL DEFAULT 0,
+	repetitions INTEGER NOT NULL DEFAULT 0,
+	due_at BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ DEFAULT now(),
+	UNIQUE (chat_id, term)
+);
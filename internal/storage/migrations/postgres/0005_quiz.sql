@@ -0,0 +1,17 @@
+-- Викторины по статьям и ответы участников
+CREATE TABLE IF NOT EXISTS quiz_polls (
+	poll_id TEXT PRIMARY KEY,
+	chat_id BIGINT NOT NULL,
+	article_id TEXT NOT NULL,
+	correct_option INTEGER NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS quiz_answers (
+	poll_id TEXT NOT NULL,
+	user_id BIGINT NOT NULL,
+	user_name TEXT,
+	correct BOOLEAN NOT NULL,
+	answered_at TIMESTAMPTZ DEFAULT now(),
+	PRIMARY KEY (poll_id, user_id)
+);
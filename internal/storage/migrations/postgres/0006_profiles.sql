@@ -0,0 +1,6 @@
+-- Профиль подписчика из Telegram и время последнего взаимодействия
+ALTER TABLE users ADD COLUMN IF NOT EXISTS first_name TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_name TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language_code TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS start_payload TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+-- Журнал действий с персональными данными, например удаления по запросу подписчика
+CREATE TABLE IF NOT EXISTS audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	chat_id BIGINT NOT NULL,
+	actor_id BIGINT NOT NULL,
+	action TEXT NOT NULL,
+	details TEXT,
+	created_at BIGINT NOT NULL
+);
@@ -0,0 +1,6 @@
+-- Подписчики бота
+CREATE TABLE IF NOT EXISTS users (
+	chat_id INTEGER PRIMARY KEY,
+	username TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,6 @@
+-- Группы и каналы как получатели рассылки со своими настройками
+ALTER TABLE users ADD COLUMN chat_type TEXT NOT NULL DEFAULT 'private';
+ALTER TABLE users ADD COLUMN title TEXT;
+ALTER TABLE users ADD COLUMN thread_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN silent INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN active INTEGER NOT NULL DEFAULT 1;
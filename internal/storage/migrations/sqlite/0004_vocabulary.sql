@@ -0,0 +1,22 @@
+-- Термины статей и личные словари подписчиков для интервального повторения
+CREATE TABLE IF NOT EXISTS article_terms (
+	article_id TEXT NOT NULL,
+	term TEXT NOT NULL,
+	translation TEXT NOT NULL,
+	PRIMARY KEY (article_id, term)
+);
+
+CREATE TABLE IF NOT EXISTS vocabulary (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	chat_id INTEGER NOT NULL,
+	term TEXT NOT NULL,
+	translation TEXT NOT NULL,
+	article_id TEXT,
+	saved INTEGER NOT NULL DEFAULT 0,
+	ease REAL NOT NULL DEFAULT 2.5,
+	interval_days INTEGER NOT NULL DEFAULT 0,
+	repetitions INTEGER NOT NULL DEFAULT 0,
+	due_at INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (chat_id, term)
+);
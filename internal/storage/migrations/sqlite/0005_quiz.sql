@@ -0,0 +1,17 @@
+-- Викторины по статьям и ответы участников
+CREATE TABLE IF NOT EXISTS quiz_polls (
+	poll_id TEXT PRIMARY KEY,
+	chat_id INTEGER NOT NULL,
+	article_id TEXT NOT NULL,
+	correct_option INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS quiz_answers (
+	poll_id TEXT NOT NULL,
+	user_id INTEGER NOT NULL,
+	user_name TEXT,
+	correct INTEGER NOT NULL,
+	answered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (poll_id, user_id)
+);
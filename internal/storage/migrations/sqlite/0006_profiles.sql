@@ -0,0 +1,6 @@
+-- Профиль подписчика из Telegram и время последнего взаимодействия
+ALTER TABLE users ADD COLUMN first_name TEXT;
+ALTER TABLE users ADD COLUMN last_name TEXT;
+ALTER TABLE users ADD COLUMN language_code TEXT;
+ALTER TABLE users ADD COLUMN start_payload TEXT;
+ALTER TABLE users ADD COLUMN last_seen_at INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+-- Журнал действий с персональными данными, например удаления по запросу подписчика
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	chat_id INTEGER NOT NULL,
+	actor_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	details TEXT,
+	created_at INTEGER NOT NULL
+);
@@ -0,0 +1,21 @@
+package storage
+
+// Export выгружает данные чата; chatID, равный 0, означает все чаты
+func (s *PostgresStore) Export(chatID int64) (*Dump, error) {
+	return exportSQL(s.db, postgresDialect, chatID)
+}
+
+// Import загружает выгрузку, обновляя уже существующие записи
+func (s *PostgresStore) Import(dump *Dump) error {
+	return importSQL(s.db, postgresDialect, dump)
+}
+
+// Forget удаляет все данные чата и записывает удаление в журнал аудита
+func (s *PostgresStore) Forget(chatID, actorID int64) (Deleted, error) {
+	deleted, err := forgetSQL(s.db, postgresDialect, chatID, actorID)
+	if err == nil {
+		s.logger.Info("Audit: chat data deleted", "chat_id", chatID, "actor_id", actorID, "deleted", deleted.String())
+	}
+
+	return deleted, err
+}
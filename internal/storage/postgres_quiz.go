@@ -0,0 +1,69 @@
+package storage
+
+import "database/sql"
+
+// RecordQuizPoll запоминает отправленную викторину и правильный ответ на неё
+func (s *PostgresStore) RecordQuizPoll(pollID string, chatID int64, articleID string, correctOption int) {
+	_, err := s.db.Exec(`
+		INSERT INTO quiz_polls (poll_id, chat_id, article_id, correct_option) VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, pollID, chatID, articleID, correctOption)
+	if err != nil {
+		s.logger.Error("Error recording quiz poll", "poll_id", pollID, "error", err)
+	}
+}
+
+// RecordQuizAnswer сохраняет ответ участника; ответы на неизвестные опросы игнорируются
+func (s *PostgresStore) RecordQuizAnswer(pollID string, userID int64, userName string, option int) error {
+	_, err := s.db.Exec(`
+		INSERT INTO quiz_answers (poll_id, user_id, user_name, correct)
+		SELECT poll_id, $2, $3, correct_option = $4 FROM quiz_polls WHERE poll_id = $1
+		ON CONFLICT DO NOTHING
+	`, pollID, userID, userName, option)
+
+	return err
+}
+
+// Leaderboard возвращает лучших участников; chatID, равный 0, означает все чаты
+func (s *PostgresStore) Leaderboard(chatID int64, limit int) ([]Score, error) {
+	rows, err := s.db.Query(`
+		SELECT a.user_id, MAX(a.user_name), COUNT(*) FILTER (WHERE a.correct), COUNT(*)
+		FROM quiz_answers a
+		JOIN quiz_polls p ON p.poll_id = a.poll_id
+		WHERE $1::BIGINT = 0 OR p.chat_id = $1
+		GROUP BY a.user_id
+		ORDER BY COUNT(*) FILTER (WHERE a.correct) DESC, COUNT(*) ASC
+		LIMIT $2
+	`, chatID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []Score
+	for rows.Next() {
+		var score Score
+		var name sql.NullString
+		if err := rows.Scan(&score.UserID, &name, &score.Correct, &score.Total); err != nil {
+			return nil, err
+		}
+		score.UserName = name.String
+		scores = append(scores, score)
+	}
+
+	return scores, rows.Err()
+}
+
+// UserScore возвращает результат одного участника; chatID, равный 0, означает все чаты
+func (s *PostgresStore) UserScore(chatID, userID int64) (Score, error) {
+	score := Score{UserID: userID}
+
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FILTER (WHERE a.correct), COUNT(*)
+		FROM quiz_answers a
+		JOIN quiz_polls p ON p.poll_id = a.poll_id
+		WHERE a.user_id = $1 AND ($2::BIGINT = 0 OR p.chat_id = $2)
+	`, userID, chatID).Scan(&score.Correct, &score.Total)
+
+	return score, err
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// PostgresStore хранит подписчиков и связанные с ними данные в PostgreSQL.
+// В отличие от SQLite, одну базу могут одновременно использовать несколько экземпляров бота,
+// поэтому согласованность обеспечивается запросами, а не блокировкой в процессе.
+type PostgresStore struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewPostgresStore подключается к PostgreSQL и применяет недостающие миграции схемы
+func NewPostgresStore(dsn string, logger *slog.Logger) (*PostgresStore, error) {
+	logger = logger.With("component", "storage", "backend", BackendPostgres)
+
+	db, err := OpenPostgres(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := NewPostgresMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		logger.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating database: %w", err)
+	}
+
+	logger.Info("PostgreSQL database initialized")
+	return &PostgresStore{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// Add добавляет пользователя или возобновляет подписку, если он от неё отказывался
+func (s *PostgresStore) Add(chatID int64) {
+	_, err := s.db.Exec(`
+		INSERT INTO users (chat_id) VALUES ($1)
+		ON CONFLICT (chat_id) DO UPDATE SET active = TRUE
+	`, chatID)
+	if err != nil {
+		s.logger.Error("Error adding user to database", "chat_id", chatID, "error", err)
+	} else {
+		s.logger.Info("User added to database", "chat_id", chatID)
+	}
+}
+
+// Subscribe добавляет подписчика или возобновляет его подписку, сохраняя профиль.
+// Параметр ссылки /start запоминается только при первом переходе, чтобы не терять источник.
+func (s *PostgresStore) Subscribe(profile Profile) {
+	_, err := s.db.Exec(`
+		INSERT INTO users (chat_id, username, first_name, last_name, language_code, chat_type, start_payload, last_seen_at, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, TRUE)
+		ON CONFLICT (chat_id) DO UPDATE SET
+			username = excluded.username,
+			first_name = excluded.first_name,
+			last_name = excluded.last_name,
+			language_code = excluded.language_code,
+			chat_type = excluded.chat_type,
+			start_payload = COALESCE(users.start_payload, excluded.start_payload),
+			last_seen_at = excluded.last_seen_at,
+			active = TRUE
+	`, profile.ChatID, profile.Username, profile.FirstName, profile.LastName, profile.LanguageCode,
+		profile.ChatType, nullString(profile.StartPayload), profile.LastSeen.Unix())
+	if err != nil {
+		s.logger.Error("Error adding user to database", "chat_id", profile.ChatID, "error", err)
+	} else {
+		s.logger.Info("User added to database", "chat_id", profile.ChatID, "username", profile.Username)
+	}
+}
+
+// Touch обновляет профиль и время последнего взаимодействия уже известного подписчика
+func (s *PostgresStore) Touch(profile Profile) {
+	_, err := s.db.Exec(`
+		UPDATE users SET username = $1, first_name = $2, last_name = $3, language_code = $4, last_seen_at = $5
+		WHERE chat_id = $6
+	`, profile.Username, profile.FirstName, profile.LastName, profile.LanguageCode,
+		profile.LastSeen.Unix(), profile.ChatID)
+	if err != nil {
+		s.logger.Error("Error updating user profile", "chat_id", profile.ChatID, "error", err)
+	}
+}
+
+// GetAll возвращает идентификаторы всех активных чатов
+func (s *PostgresStore) GetAll() []int64 {
+	var users []int64
+
+	rows, err := s.db.Query("SELECT chat_id FROM users WHERE active")
+	if err != nil {
+		s.logger.Error("Error retrieving users from database", "error", err)
+		return users
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			s.logger.Error("Error scanning user row", "error", err)
+			continue
+		}
+		users = append(users, chatID)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error("Error iterating user rows", "error", err)
+	}
+
+	s.logger.Debug("Retrieved users from database", "count", len(users))
+	return users
+}
+
+// AddTarget регистрирует группу или канал как получателя рассылки.
+// Если чат уже зарегистрирован, его настройки обновляются и подписка возобновляется.
+func (s *PostgresStore) AddTarget(target Target) {
+	_, err := s.db.Exec(`
+		INSERT INTO users (chat_id, chat_type, title, thread_id, silent, active)
+		VALUES ($1, $2, $3, $4, $5, TRUE)
+		ON CONFLICT (chat_id) DO UPDATE SET
+			chat_type = excluded.chat_type,
+			title = excluded.title,
+			thread_id = excluded.thread_id,
+			silent = excluded.silent,
+			active = TRUE
+	`, target.ChatID, target.Type, target.Title, target.ThreadID, target.Silent)
+	if err != nil {
+		s.logger.Error("Error adding chat to database", "chat_type", target.Type, "chat_id", target.ChatID, "error", err)
+	} else {
+		s.logger.Info("Chat added to database", "chat_type", target.Type, "chat_id", target.ChatID, "title", target.Title)
+	}
+}
+
+// Get возвращает настройки получателя; ok равен false, если чат не зарегистрирован
+func (s *PostgresStore) Get(chatID int64) (target Target, ok bool) {
+	var title sql.NullString
+	err := s.db.QueryRow(`
+		SELECT chat_id, chat_type, title, thread_id, silent FROM users WHERE chat_id = $1 AND active
+	`, chatID).Scan(&target.ChatID, &target.Type, &title, &target.ThreadID, &target.Silent)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.logger.Error("Error retrieving chat from database", "chat_id", chatID, "error", err)
+		}
+		return Target{}, false
+	}
+	target.Title = title.String
+
+	return target, true
+}
+
+// Deactivate отключает доставку в чат, сохраняя его настройки
+func (s *PostgresStore) Deactivate(chatID int64) {
+	_, err := s.db.Exec("UPDATE users SET active = FALSE WHERE chat_id = $1", chatID)
+	if err != nil {
+		s.logger.Error("Error deactivating chat", "chat_id", chatID, "error", err)
+	} else {
+		s.logger.Info("Chat deactivated", "chat_id", chatID)
+	}
+}
+
+// GetTargets возвращает всех активных получателей рассылки вместе с их настройками
+func (s *PostgresStore) GetTargets() []Target {
+	var targets []Target
+
+	rows, err := s.db.Query("SELECT chat_id, chat_type, title, thread_id, silent FROM users WHERE active ORDER BY chat_id")
+	if err != nil {
+		s.logger.Error("Error retrieving targets from database", "error", err)
+		return targets
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var target Target
+		var title sql.NullString
+		if err := rows.Scan(&target.ChatID, &target.Type, &title, &target.ThreadID, &target.Silent); err != nil {
+			s.logger.Error("Error scanning target row", "error", err)
+			continue
+		}
+		target.Title = title.String
+		targets = append(targets, target)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error("Error iterating target rows", "error", err)
+	}
+
+	return targets
+}
+
+// Count возвращает количество активных чатов
+func (s *PostgresStore) Count() int {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE active").Scan(&count)
+	if err != nil {
+		s.logger.Error("Error counting users", "error", err)
+		return 0
+	}
+
+	return count
+}
+
+// Ping проверяет соединение с базой данных
+func (s *PostgresStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Close закрывает пул соединений с базой данных
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RecordTerms сохраняет термины статьи и добавляет их в историю каждого получателя.
+// Уже известные подписчику термины не перезаписываются, чтобы не сбросить прогресс.
+func (s *PostgresStore) RecordTerms(articleID string, translations map[string]string, chatIDs []int64) {
+	if len(translations) == 0 {
+		return
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error("Error starting terms transaction", "error", err)
+		return
+	}
+	defer tx.Rollback()
+
+	for term, translation := range translations {
+		if _, err := tx.Exec(`
+			INSERT INTO article_terms (article_id, term, translation) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+		`, articleID, term, translation); err != nil {
+			s.logger.Error("Error recording article term", "term", term, "article_id", articleID, "error", err)
+			return
+		}
+
+		for _, chatID := range chatIDs {
+			if _, err := tx.Exec(`
+				INSERT INTO vocabulary (chat_id, term, translation, article_id) VALUES ($1, $2, $3, $4)
+				ON CONFLICT DO NOTHING
+			`, chatID, term, translation, articleID); err != nil {
+				s.logger.Error("Error recording term for chat", "term", term, "chat_id", chatID, "error", err)
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Error committing article terms", "article_id", articleID, "error", err)
+	}
+}
+
+// SaveTerms добавляет термины статьи в словарь для повторения и возвращает их количество
+func (s *PostgresStore) SaveTerms(chatID int64, articleID string) (int, error) {
+	res, err := s.db.Exec(`
+		INSERT INTO vocabulary (chat_id, term, translation, article_id, saved, due_at)
+		SELECT $1, term, translation, article_id, TRUE, $2 FROM article_terms WHERE article_id = $3
+		ON CONFLICT (chat_id, term) DO UPDATE SET
+			saved = TRUE,
+			due_at = CASE WHEN vocabulary.saved THEN vocabulary.due_at ELSE excluded.due_at END
+	`, chatID, time.Now().Unix(), articleID)
+	if err != nil {
+		return 0, err
+	}
+
+	saved, err := res.RowsAffected()
+	return int(saved), err
+}
+
+// NextDueCard возвращает ближайшую карточку к повторению; nil, если повторять нечего
+func (s *PostgresStore) NextDueCard(chatID int64, now time.Time) (*Card, error) {
+	card, err := scanCard(s.db.QueryRow(`
+		SELECT id, chat_id, term, translation, ease, interval_days, repetitions, due_at
+		FROM vocabulary
+		WHERE chat_id = $1 AND saved AND due_at <= $2
+		ORDER BY due_at
+		LIMIT 1
+	`, chatID, now.Unix()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return card, err
+}
+
+// Card возвращает карточку по идентификатору
+func (s *PostgresStore) Card(id int64) (*Card, error) {
+	return scanCard(s.db.QueryRow(`
+		SELECT id, chat_id, term, translation, ease, interval_days, repetitions, due_at
+		FROM vocabulary WHERE id = $1
+	`, id))
+}
+
+// UpdateCard сохраняет новое состояние интервального повторения карточки
+func (s *PostgresStore) UpdateCard(card *Card) error {
+	_, err := s.db.Exec(`
+		UPDATE vocabulary SET ease = $1, interval_days = $2, repetitions = $3, due_at = $4 WHERE id = $5
+	`, card.Ease, card.Interval, card.Repetitions, card.DueAt.Unix(), card.ID)
+
+	return err
+}
+
+// VocabularyStats возвращает число сохранённых терминов, число терминов к повторению
+// и последние сохранённые термины
+func (s *PostgresStore) VocabularyStats(chatID int64, now time.Time, limit int) (saved, due int, recent []Card, err error) {
+	err = s.db.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE due_at <= $1)
+		FROM vocabulary WHERE chat_id = $2 AND saved
+	`, now.Unix(), chatID).Scan(&saved, &due)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, chat_id, term, translation, ease, interval_days, repetitions, due_at
+		FROM vocabulary WHERE chat_id = $1 AND saved
+		ORDER BY id DESC
+		LIMIT $2
+	`, chatID, limit)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		recent = append(recent, *card)
+	}
+
+	return saved, due, recent, rows.Err()
+}
+
+// ChatsWithDueCards возвращает чаты, в которых есть термины к повторению, и их количество
+func (s *PostgresStore) ChatsWithDueCards(now time.Time) (map[int64]int, error) {
+	rows, err := s.db.Query(`
+		SELECT v.chat_id, COUNT(*)
+		FROM vocabulary v
+		JOIN users u ON u.chat_id = v.chat_id AND u.active
+		WHERE v.saved AND v.due_at <= $1
+		GROUP BY v.chat_id
+	`, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chats := make(map[int64]int)
+	for rows.Next() {
+		var chatID int64
+		var due int
+		if err := rows.Scan(&chatID, &due); err != nil {
+			return nil, err
+		}
+		chats[chatID] = due
+	}
+
+	return chats, rows.Err()
+}
@@ -0,0 +1,59 @@
+package storage
+
+import "strings"
+
+// Виды отзывов: оценка статьи и пожелание присылать больше или меньше похожих
+const (
+	FeedbackVote       = "vote"
+	FeedbackPreference = "preference"
+)
+
+// feedbackWeight возвращает вес отзыва: явное пожелание «больше/меньше такого»
+// весит больше, чем оценка
+func feedbackWeight(kind string) float64 {
+	if kind == FeedbackPreference {
+		return 2.0
+	}
+	return 1.0
+}
+
+// preferenceAccumulator считает средневзвешенную оценку по ключам
+type preferenceAccumulator struct {
+	sum    map[string]float64
+	weight map[string]float64
+}
+
+func newPreferenceAccumulator() *preferenceAccumulator {
+	return &preferenceAccumulator{
+		sum:    make(map[string]float64),
+		weight: make(map[string]float64),
+	}
+}
+
+func (p *preferenceAccumulator) add(key string, value int, weight float64) {
+	if key == "" {
+		return
+	}
+	p.sum[key] += float64(value) * weight
+	p.weight[key] += weight
+}
+
+// result возвращает оценки в диапазоне (-1, 1); сглаживание не даёт
+// единичным отзывам сильно влиять на выбор статьи
+func (p *preferenceAccumulator) result() map[string]float64 {
+	const smoothing = 2.0
+
+	result := make(map[string]float64, len(p.sum))
+	for key, sum := range p.sum {
+		result[key] = sum / (p.weight[key] + smoothing)
+	}
+
+	return result
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
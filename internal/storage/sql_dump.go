@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Общая для SQLite и PostgreSQL реализация выгрузки, загрузки и удаления данных чата.
+// Запросы пишутся с параметрами ?, которые dialect.bind переводит в формат базы.
+// Сравнение с нулём через CAST нужно PostgreSQL, чтобы параметр имел тип BIGINT.
+
+// Deleted — число удалённых записей по таблицам
+type Deleted map[string]int64
+
+// String возвращает сводку вида "feedback=2 users=1"
+func (d Deleted) String() string {
+	parts := make([]string, 0, len(d))
+	for table, count := range d {
+		parts = append(parts, fmt.Sprintf("%s=%d", table, count))
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, " ")
+}
+
+// Total возвращает общее число удалённых записей
+func (d Deleted) Total() int64 {
+	var total int64
+	for _, count := range d {
+		total += count
+	}
+
+	return total
+}
+
+// exportSQL выгружает данные чата; chatID, равный 0, означает все чаты
+func exportSQL(db *sql.DB, d dialect, chatID int64) (*Dump, error) {
+	dump := newDump()
+
+	err := queryEach(db, d, `
+		SELECT chat_id, chat_type, COALESCE(title, ''), thread_id, silent, active,
+			COALESCE(username, ''), COALESCE(first_name, ''), COALESCE(last_name, ''),
+			COALESCE(language_code, ''), COALESCE(start_payload, ''), last_seen_at, created_at
+		FROM users
+		WHERE CAST(? AS BIGINT) = 0 OR chat_id = ?
+		ORDER BY chat_id
+	`, []any{chatID, chatID}, func(row rowScanner) error {
+		var u UserRecord
+		var lastSeen int64
+		var createdAt sql.NullTime
+		if err := row.Scan(&u.ChatID, &u.ChatType, &u.Title, &u.ThreadID, &u.Silent, &u.Active,
+			&u.Username, &u.FirstName, &u.LastName, &u.LanguageCode, &u.StartPayload, &lastSeen, &createdAt); err != nil {
+			return err
+		}
+		u.LastSeen = unixTime(lastSeen)
+		u.CreatedAt = createdAt.Time
+		dump.Users = append(dump.Users, u)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting users: %w", err)
+	}
+
+	err = queryEach(db, d, `
+		SELECT article_id, url, COALESCE(title, ''), COALESCE(source, ''), COALESCE(topics, ''),
+			COALESCE(keywords, ''), delivered_at
+		FROM articles
+		WHERE CAST(? AS BIGINT) = 0
+			OR article_id IN (SELECT article_id FROM feedback WHERE chat_id = ? OR user_id = ?)
+		ORDER BY delivered_at
+	`, []any{chatID, chatID, chatID}, func(row rowScanner) error {
+		var a ArticleRecord
+		var deliveredAt sql.NullTime
+		if err := row.Scan(&a.ArticleID, &a.URL, &a.Title, &a.Source, &a.Topics, &a.Keywords, &deliveredAt); err != nil {
+			return err
+		}
+		a.DeliveredAt = deliveredAt.Time
+		dump.Articles = append(dump.Articles, a)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting articles: %w", err)
+	}
+
+	err = queryEach(db, d, `
+		SELECT chat_id, user_id, article_id, kind, value, created_at
+		FROM feedback
+		WHERE CAST(? AS BIGINT) = 0 OR chat_id = ? OR user_id = ?
+		ORDER BY created_at
+	`, []any{chatID, chatID, chatID}, func(row rowScanner) error {
+		var f FeedbackRecord
+		var createdAt sql.NullTime
+		if err := row.Scan(&f.ChatID, &f.UserID, &f.ArticleID, &f.Kind, &f.Value, &createdAt); err != nil {
+			return err
+		}
+		f.CreatedAt = createdAt.Time
+		dump.Feedback = append(dump.Feedback, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting feedback: %w", err)
+	}
+
+	err = queryEach(db, d, `
+		SELECT chat_id, term, translation, COALESCE(article_id, ''), saved, ease, interval_days, repetitions, due_at
+		FROM vocabulary
+		WHERE CAST(? AS BIGINT) = 0 OR chat_id = ?
+		ORDER BY chat_id, id
+	`, []any{chatID, chatID}, func(row rowScanner) error {
+		var v VocabularyRecord
+		var dueAt int64
+		if err := row.Scan(&v.ChatID, &v.Term, &v.Translation, &v.ArticleID, &v.Saved,
+			&v.Ease, &v.Interval, &v.Repetitions, &dueAt); err != nil {
+			return err
+		}
+		v.DueAt = unixTime(dueAt)
+		dump.Vocabulary = append(dump.Vocabulary, v)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error exporting vocabulary: %w", err)
+	}
+
+	return dump, nil
+}
+
+// importSQL загружает выгрузку в одной транзакции. Существующие записи обновляются,
+// статьи из журнала доставки не перезаписываются.
+func importSQL(db *sql.DB, d dialect, dump *Dump) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, u := range dump.Users {
+		createdAt := u.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		_, err := tx.Exec(d.bind(`
+			INSERT INTO users (chat_id, chat_type, title, thread_id, silent, active, username, first_name,
+				last_name, language_code, start_payload, last_seen_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (chat_id) DO UPDATE SET
+				chat_type = excluded.chat_type,
+				title = excluded.title,
+				thread_id = excluded.thread_id,
+				silent = excluded.silent,
+				active = excluded.active,
+				username = excluded.username,
+				first_name = excluded.first_name,
+				last_name = excluded.last_name,
+				language_code = excluded.language_code,
+				start_payload = excluded.start_payload,
+				last_seen_at = excluded.last_seen_at
+		`), u.ChatID, u.ChatType, nullString(u.Title), u.ThreadID, u.Silent, u.Active, nullString(u.Username),
+			nullString(u.FirstName), nullString(u.LastName), nullString(u.LanguageCode), nullString(u.StartPayload),
+			unixSeconds(u.LastSeen), createdAt)
+		if err != nil {
+			return fmt.Errorf("error importing user %d: %w", u.ChatID, err)
+		}
+	}
+
+	for _, a := range dump.Articles {
+		deliveredAt := a.DeliveredAt
+		if deliveredAt.IsZero() {
+			deliveredAt = time.Now()
+		}
+
+		_, err := tx.Exec(d.bind(`
+			INSERT INTO articles (article_id, url, title, source, topics, keywords, delivered_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (article_id) DO NOTHING
+		`), a.ArticleID, a.URL, a.Title, a.Source, a.Topics, a.Keywords, deliveredAt)
+		if err != nil {
+			return fmt.Errorf("error importing article %s: %w", a.ArticleID, err)
+		}
+	}
+
+	for _, f := range dump.Feedback {
+		createdAt := f.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		_, err := tx.Exec(d.bind(`
+			INSERT INTO feedback (chat_id, user_id, article_id, kind, value, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (chat_id, user_id, article_id, kind) DO UPDATE SET
+				value = excluded.value,
+				created_at = excluded.created_at
+		`), f.ChatID, f.UserID, f.ArticleID, f.Kind, f.Value, createdAt)
+		if err != nil {
+			return fmt.Errorf("error importing feedback of user %d: %w", f.UserID, err)
+		}
+	}
+
+	for _, v := range dump.Vocabulary {
+		_, err := tx.Exec(d.bind(`
+			INSERT INTO vocabulary (chat_id, term, translation, article_id, saved, ease, interval_days, repetitions, due_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (chat_id, term) DO UPDATE SET
+				translation = excluded.translation,
+				article_id = excluded.article_id,
+				saved = excluded.saved,
+				ease = excluded.ease,
+				interval_days = excluded.interval_days,
+				repetitions = excluded.repetitions,
+				due_at = excluded.due_at
+		`), v.ChatID, v.Term, v.Translation, nullString(v.ArticleID), v.Saved, v.Ease, v.Interval,
+			v.Repetitions, unixSeconds(v.DueAt))
+		if err != nil {
+			return fmt.Errorf("error importing term %q of chat %d: %w", v.Term, v.ChatID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// forgetQueries удаляет всё, что связано с чатом: для личного чата идентификатор чата
+// совпадает с идентификатором пользователя, поэтому удаляются и его ответы и отзывы в группах
+var forgetQueries = []struct {
+	table string
+	query string
+	args  int
+}{
+	{"quiz_answers", "DELETE FROM quiz_answers WHERE user_id = ? OR poll_id IN (SELECT poll_id FROM quiz_polls WHERE chat_id = ?)", 2},
+	{"quiz_polls", "DELETE FROM quiz_polls WHERE chat_id = ?", 1},
+	{"feedback", "DELETE FROM feedback WHERE chat_id = ? OR user_id = ?", 2},
+	{"vocabulary", "DELETE FROM vocabulary WHERE chat_id = ?", 1},
+	{"users", "DELETE FROM users WHERE chat_id = ?", 1},
+}
+
+// forgetSQL удаляет данные чата и записывает удаление в журнал аудита в одной транзакции
+func forgetSQL(db *sql.DB, d dialect, chatID, actorID int64) (Deleted, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deleted := make(Deleted)
+	for _, q := range forgetQueries {
+		args := make([]any, q.args)
+		for i := range args {
+			args[i] = chatID
+		}
+
+		res, err := tx.Exec(d.bind(q.query), args...)
+		if err != nil {
+			return nil, fmt.Errorf("error deleting from %s: %w", q.table, err)
+		}
+		count, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		deleted[q.table] = count
+	}
+
+	_, err = tx.Exec(d.bind(`
+		INSERT INTO audit_log (chat_id, actor_id, action, details, created_at) VALUES (?, ?, ?, ?, ?)
+	`), chatID, actorID, AuditForget, deleted.String(), time.Now().Unix())
+	if err != nil {
+		return nil, fmt.Errorf("error writing audit log: %w", err)
+	}
+
+	return deleted, tx.Commit()
+}
+
+// queryEach выполняет запрос и передаёт каждую строку результата в scan
+func queryEach(db *sql.DB, d dialect, query string, args []any, scan func(rowScanner) error) error {
+	rows, err := db.Query(d.bind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// unixTime переводит секунды Unix во время; 0 означает, что значения нет
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// unixSeconds — обратное к unixTime преобразование
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,30 @@
+package storage
+
+// Export выгружает данные чата; chatID, равный 0, означает все чаты
+func (s *SQLiteStore) Export(chatID int64) (*Dump, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return exportSQL(s.db, sqliteDialect, chatID)
+}
+
+// Import загружает выгрузку, обновляя уже существующие записи
+func (s *SQLiteStore) Import(dump *Dump) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return importSQL(s.db, sqliteDialect, dump)
+}
+
+// Forget удаляет все данные чата и записывает удаление в журнал аудита
+func (s *SQLiteStore) Forget(chatID, actorID int64) (Deleted, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted, err := forgetSQL(s.db, sqliteDialect, chatID, actorID)
+	if err == nil {
+		s.logger.Info("Audit: chat data deleted", "chat_id", chatID, "actor_id", actorID, "deleted", deleted.String())
+	}
+
+	return deleted, err
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"strings"
+
+	"github.com/andrei/goBot/internal/news"
+)
+
+// RecordDelivery добавляет статью в журнал доставленных, чтобы связать с ней отзывы
+func (s *SQLiteStore) RecordDelivery(article *news.Article, keywords []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO articles (article_id, url, title, source, topics, keywords)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(article_id) DO UPDATE SET delivered_at = CURRENT_TIMESTAMP
+	`, article.ID(), article.URL, article.Title, article.Source.Name,
+		strings.Join(article.Topics(), ","), strings.Join(keywords, ","))
+	if err != nil {
+		s.logger.Error("Error recording article delivery", "article_url", article.URL, "error", err)
+	}
+}
+
+// RecordFeedback сохраняет отзыв; повторное нажатие заменяет предыдущий отзыв того же вида
+func (s *SQLiteStore) RecordFeedback(chatID, userID int64, articleID, kind string, value int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO feedback (chat_id, user_id, article_id, kind, value)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(chat_id, user_id, article_id, kind) DO UPDATE SET
+			value = excluded.value,
+			created_at = CURRENT_TIMESTAMP
+	`, chatID, userID, articleID, kind, value)
+
+	return err
+}
+
+// Preferences агрегирует отзывы по источникам, темам и ключевым терминам статей
+func (s *SQLiteStore) Preferences() *news.Preferences {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query(`
+		SELECT a.source, a.topics, a.keywords, f.kind, f.value
+		FROM feedback f
+		JOIN articles a ON a.article_id = f.article_id
+	`)
+	if err != nil {
+		s.logger.Error("Error retrieving feedback from database", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	sources := newPreferenceAccumulator()
+	topics := newPreferenceAccumulator()
+	keywords := newPreferenceAccumulator()
+
+	for rows.Next() {
+		var source, topicList, keywordList, kind string
+		var value int
+		if err := rows.Scan(&source, &topicList, &keywordList, &kind, &value); err != nil {
+			s.logger.Error("Error scanning feedback row", "error", err)
+			continue
+		}
+
+		weight := feedbackWeight(kind)
+
+		sources.add(source, value, weight)
+		for _, topic := range splitList(topicList) {
+			topics.add(topic, value, weight)
+		}
+		for _, keyword := range splitList(keywordList) {
+			keywords.add(keyword, value, weight)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error("Error iterating feedback rows", "error", err)
+	}
+
+	return &news.Preferences{
+		Sources:  sources.result(),
+		Topics:   topics.result(),
+		Keywords: keywords.result(),
+	}
+}
@@ -0,0 +1,87 @@
+package storage
+
+import "database/sql"
+
+// RecordQuizPoll запоминает отправленную викторину и правильный ответ на неё
+func (s *SQLiteStore) RecordQuizPoll(pollID string, chatID int64, articleID string, correctOption int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO quiz_polls (poll_id, chat_id, article_id, correct_option) VALUES (?, ?, ?, ?)
+	`, pollID, chatID, articleID, correctOption)
+	if err != nil {
+		s.logger.Error("Error recording quiz poll", "poll_id", pollID, "error", err)
+	}
+}
+
+// RecordQuizAnswer сохраняет ответ участника; ответы на неизвестные опросы игнорируются
+func (s *SQLiteStore) RecordQuizAnswer(pollID string, userID int64, userName string, option int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var correctOption int
+	err := s.db.QueryRow("SELECT correct_option FROM quiz_polls WHERE poll_id = ?", pollID).Scan(&correctOption)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT OR IGNORE INTO quiz_answers (poll_id, user_id, user_name, correct) VALUES (?, ?, ?, ?)
+	`, pollID, userID, userName, option == correctOption)
+
+	return err
+}
+
+// Leaderboard возвращает лучших участников; chatID, равный 0, означает все чаты
+func (s *SQLiteStore) Leaderboard(chatID int64, limit int) ([]Score, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query(`
+		SELECT a.user_id, MAX(a.user_name), SUM(a.correct), COUNT(*)
+		FROM quiz_answers a
+		JOIN quiz_polls p ON p.poll_id = a.poll_id
+		WHERE ? = 0 OR p.chat_id = ?
+		GROUP BY a.user_id
+		ORDER BY SUM(a.correct) DESC, COUNT(*) ASC
+		LIMIT ?
+	`, chatID, chatID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []Score
+	for rows.Next() {
+		var score Score
+		var name sql.NullString
+		if err := rows.Scan(&score.UserID, &name, &score.Correct, &score.Total); err != nil {
+			return nil, err
+		}
+		score.UserName = name.String
+		scores = append(scores, score)
+	}
+
+	return scores, rows.Err()
+}
+
+// UserScore возвращает результат одного участника; chatID, равный 0, означает все чаты
+func (s *SQLiteStore) UserScore(chatID, userID int64) (Score, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score := Score{UserID: userID}
+
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(a.correct), 0), COUNT(*)
+		FROM quiz_answers a
+		JOIN quiz_polls p ON p.poll_id = a.poll_id
+		WHERE a.user_id = ? AND (? = 0 OR p.chat_id = ?)
+	`, userID, chatID, chatID).Scan(&score.Correct, &score.Total)
+
+	return score, err
+}
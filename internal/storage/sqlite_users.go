@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SQLiteStore хранит подписчиков и связанные с ними данные в базе SQLite
+type SQLiteStore struct {
+	db     *sql.DB
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
+// NewSQLiteStore открывает базу SQLite и применяет к ней недостающие миграции схемы
+func NewSQLiteStore(dbPath string, logger *slog.Logger) (*SQLiteStore, error) {
+	logger = logger.With("component", "storage", "backend", BackendSQLite)
+	
+	// Создаем директорию для БД, если она не существует
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		logger.Warn("Failed to create data directory, using the working directory", "error", err)
+		// Если не получается создать директорию, используем текущую
+		dbPath = filepath.Base(dbPath)
+	}
+	
+	db, err := OpenSQLite(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	
+	migrator, err := NewSQLiteMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		logger.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating database: %w", err)
+	}
+	
+	logger.Info("SQLite database initialized", "path", dbPath)
+	return &SQLiteStore{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// Add добавляет пользователя в хранилище
+func (s *SQLiteStore) Add(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	// Добавляем пользователя или возобновляем подписку, если он от неё отказывался
+	_, err := s.db.Exec(`
+		INSERT INTO users (chat_id) VALUES (?)
+		ON CONFLICT(chat_id) DO UPDATE SET active = 1
+	`, chatID)
+	if err != nil {
+		s.logger.Error("Error adding user to database", "chat_id", chatID, "error", err)
+	} else {
+		s.logger.Info("User added to database", "chat_id", chatID)
+	}
+}
+
+// Subscribe добавляет подписчика или возобновляет его подписку, сохраняя профиль.
+// Параметр ссылки /start запоминается только при первом переходе, чтобы не терять источник.
+func (s *SQLiteStore) Subscribe(profile Profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO users (chat_id, username, first_name, last_name, language_code, chat_type, start_payload, last_seen_at, active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 1)
+		ON CONFLICT(chat_id) DO UPDATE SET
+			username = excluded.username,
+			first_name = excluded.first_name,
+			last_name = excluded.last_name,
+			language_code = excluded.language_code,
+			chat_type = excluded.chat_type,
+			start_payload = COALESCE(users.start_payload, excluded.start_payload),
+			last_seen_at = excluded.last_seen_at,
+			active = 1
+	`, profile.ChatID, profile.Username, profile.FirstName, profile.LastName, profile.LanguageCode,
+		profile.ChatType, nullString(profile.StartPayload), profile.LastSeen.Unix())
+
+	if err != nil {
+		s.logger.Error("Error adding user to database", "chat_id", profile.ChatID, "error", err)
+	} else {
+		s.logger.Info("User added to database", "chat_id", profile.ChatID, "username", profile.Username)
+	}
+}
+
+// Touch обновляет профиль и время последнего взаимодействия уже известного подписчика
+func (s *SQLiteStore) Touch(profile Profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		UPDATE users SET username = ?, first_name = ?, last_name = ?, language_code = ?, last_seen_at = ?
+		WHERE chat_id = ?
+	`, profile.Username, profile.FirstName, profile.LastName, profile.LanguageCode,
+		profile.LastSeen.Unix(), profile.ChatID)
+	if err != nil {
+		s.logger.Error("Error updating user profile", "chat_id", profile.ChatID, "error", err)
+	}
+}
+
+// GetAll возвращает список всех пользователей
+func (s *SQLiteStore) GetAll() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	var users []int64
+	
+	// Получаем всех активных пользователей из базы
+	rows, err := s.db.Query("SELECT chat_id FROM users WHERE active = 1")
+	if err != nil {
+		s.logger.Error("Error retrieving users from database", "error", err)
+		return users
+	}
+	defer rows.Close()
+	
+	// Обрабатываем результаты
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			s.logger.Error("Error scanning user row", "error", err)
+			continue
+		}
+		users = append(users, chatID)
+	}
+	
+	if err := rows.Err(); err != nil {
+		s.logger.Error("Error iterating user rows", "error", err)
+	}
+	
+	s.logger.Debug("Retrieved users from database", "count", len(users))
+	return users
+}
+
+// AddTarget регистрирует группу или канал как получателя рассылки.
+// Если чат уже зарегистрирован, его настройки обновляются и подписка возобновляется.
+func (s *SQLiteStore) AddTarget(target Target) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO users (chat_id, chat_type, title, thread_id, silent, active)
+		VALUES (?, ?, ?, ?, ?, 1)
+		ON CONFLICT(chat_id) DO UPDATE SET
+			chat_type = excluded.chat_type,
+			title = excluded.title,
+			thread_id = excluded.thread_id,
+			silent = excluded.silent,
+			active = 1
+	`, target.ChatID, target.Type, target.Title, target.ThreadID, target.Silent)
+
+	if err != nil {
+		s.logger.Error("Error adding chat to database", "chat_type", target.Type, "chat_id", target.ChatID, "error", err)
+	} else {
+		s.logger.Info("Chat added to database", "chat_type", target.Type, "chat_id", target.ChatID, "title", target.Title)
+	}
+}
+
+// Get возвращает настройки получателя; ok равен false, если чат не зарегистрирован
+func (s *SQLiteStore) Get(chatID int64) (target Target, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var title sql.NullString
+	err := s.db.QueryRow(`
+		SELECT chat_id, chat_type, title, thread_id, silent FROM users WHERE chat_id = ? AND active = 1
+	`, chatID).Scan(&target.ChatID, &target.Type, &title, &target.ThreadID, &target.Silent)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.logger.Error("Error retrieving chat from database", "chat_id", chatID, "error", err)
+		}
+		return Target{}, false
+	}
+	target.Title = title.String
+
+	return target, true
+}
+
+// Deactivate отключает доставку в чат, сохраняя его настройки
+func (s *SQLiteStore) Deactivate(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec("UPDATE users SET active = 0 WHERE chat_id = ?", chatID)
+	if err != nil {
+		s.logger.Error("Error deactivating chat", "chat_id", chatID, "error", err)
+	} else {
+		s.logger.Info("Chat deactivated", "chat_id", chatID)
+	}
+}
+
+// GetTargets возвращает всех активных получателей рассылки вместе с их настройками
+func (s *SQLiteStore) GetTargets() []Target {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var targets []Target
+
+	rows, err := s.db.Query("SELECT chat_id, chat_type, title, thread_id, silent FROM users WHERE active = 1")
+	if err != nil {
+		s.logger.Error("Error retrieving targets from database", "error", err)
+		return targets
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var target Target
+		var title sql.NullString
+		if err := rows.Scan(&target.ChatID, &target.Type, &title, &target.ThreadID, &target.Silent); err != nil {
+			s.logger.Error("Error scanning target row", "error", err)
+			continue
+		}
+		target.Title = title.String
+		targets = append(targets, target)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error("Error iterating target rows", "error", err)
+	}
+
+	return targets
+}
+
+// Count возвращает количество пользователей
+func (s *SQLiteStore) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE active = 1").Scan(&count)
+	if err != nil {
+		s.logger.Error("Error counting users", "error", err)
+		return 0
+	}
+	
+	return count
+}
+
+// Ping проверяет соединение с базой данных
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Close закрывает соединение с базой данных
+func (s *SQLiteStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	return s.db.Close()
+}
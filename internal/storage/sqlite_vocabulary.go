@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RecordTerms сохраняет термины статьи и добавляет их в историю каждого получателя.
+// Уже известные подписчику термины не перезаписываются, чтобы не сбросить прогресс.
+func (s *SQLiteStore) RecordTerms(articleID string, translations map[string]string, chatIDs []int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(translations) == 0 {
+		return
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error("Error starting terms transaction", "error", err)
+		return
+	}
+	defer tx.Rollback()
+
+	for term, translation := range translations {
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO article_terms (article_id, term, translation) VALUES (?, ?, ?)
+		`, articleID, term, translation); err != nil {
+			s.logger.Error("Error recording article term", "term", term, "article_id", articleID, "error", err)
+			return
+		}
+
+		for _, chatID := range chatIDs {
+			if _, err := tx.Exec(`
+				INSERT OR IGNORE INTO vocabulary (chat_id, term, translation, article_id) VALUES (?, ?, ?, ?)
+			`, chatID, term, translation, articleID); err != nil {
+				s.logger.Error("Error recording term for chat", "term", term, "chat_id", chatID, "error", err)
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Error committing article terms", "article_id", articleID, "error", err)
+	}
+}
+
+// SaveTerms добавляет термины статьи в словарь для повторения и возвращает их количество
+func (s *SQLiteStore) SaveTerms(chatID int64, articleID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`
+		INSERT INTO vocabulary (chat_id, term, translation, article_id, saved, due_at)
+		SELECT ?, term, translation, article_id, 1, ? FROM article_terms WHERE article_id = ?
+		ON CONFLICT(chat_id, term) DO UPDATE SET
+			saved = 1,
+			due_at = CASE WHEN vocabulary.saved = 1 THEN vocabulary.due_at ELSE excluded.due_at END
+	`, chatID, time.Now().Unix(), articleID)
+	if err != nil {
+		return 0, err
+	}
+
+	saved, err := res.RowsAffected()
+	return int(saved), err
+}
+
+// NextDueCard возвращает ближайшую карточку к повторению; nil, если повторять нечего
+func (s *SQLiteStore) NextDueCard(chatID int64, now time.Time) (*Card, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card, err := scanCard(s.db.QueryRow(`
+		SELECT id, chat_id, term, translation, ease, interval_days, repetitions, due_at
+		FROM vocabulary
+		WHERE chat_id = ? AND saved = 1 AND due_at <= ?
+		ORDER BY due_at
+		LIMIT 1
+	`, chatID, now.Unix()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return card, err
+}
+
+// Card возвращает карточку по идентификатору
+func (s *SQLiteStore) Card(id int64) (*Card, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return scanCard(s.db.QueryRow(`
+		SELECT id, chat_id, term, translation, ease, interval_days, repetitions, due_at
+		FROM vocabulary WHERE id = ?
+	`, id))
+}
+
+// UpdateCard сохраняет новое состояние интервального повторения карточки
+func (s *SQLiteStore) UpdateCard(card *Card) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		UPDATE vocabulary SET ease = ?, interval_days = ?, repetitions = ?, due_at = ? WHERE id = ?
+	`, card.Ease, card.Interval, card.Repetitions, card.DueAt.Unix(), card.ID)
+
+	return err
+}
+
+// VocabularyStats возвращает число сохранённых терминов, число терминов к повторению
+// и последние сохранённые термины
+func (s *SQLiteStore) VocabularyStats(chatID int64, now time.Time, limit int) (saved, due int, recent []Card, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN due_at <= ? THEN 1 ELSE 0 END), 0)
+		FROM vocabulary WHERE chat_id = ? AND saved = 1
+	`, now.Unix(), chatID).Scan(&saved, &due)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, chat_id, term, translation, ease, interval_days, repetitions, due_at
+		FROM vocabulary WHERE chat_id = ? AND saved = 1
+		ORDER BY id DESC
+		LIMIT ?
+	`, chatID, limit)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		recent = append(recent, *card)
+	}
+
+	return saved, due, recent, rows.Err()
+}
+
+// ChatsWithDueCards возвращает чаты, в которых есть термины к повторению, и их количество
+func (s *SQLiteStore) ChatsWithDueCards(now time.Time) (map[int64]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query(`
+		SELECT v.chat_id, COUNT(*)
+		FROM vocabulary v
+		JOIN users u ON u.chat_id = v.chat_id AND u.active = 1
+		WHERE v.saved = 1 AND v.due_at <= ?
+		GROUP BY v.chat_id
+	`, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chats := make(map[int64]int)
+	for rows.Next() {
+		var chatID int64
+		var due int
+		if err := rows.Scan(&chatID, &due); err != nil {
+			return nil, err
+		}
+		chats[chatID] = due
+	}
+
+	return chats, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCard(row rowScanner) (*Card, error) {
+	var card Card
+	var dueAt int64
+	if err := row.Scan(&card.ID, &card.ChatID, &card.Term, &card.Translation,
+		&card.Ease, &card.Interval, &card.Repetitions, &dueAt); err != nil {
+		return nil, err
+	}
+	card.DueAt = time.Unix(dueAt, 0)
+
+	return &card, nil
+}
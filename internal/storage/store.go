@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andrei/goBot/internal/news"
+)
+
+// UserStore — хранилище подписчиков и связанных с ними данных: настроек доставки,
+// журнала статей, отзывов, словарей и результатов викторин
+type UserStore interface {
+	// Подписчики и получатели рассылки
+	Add(chatID int64)
+	Subscribe(profile Profile)
+	Touch(profile Profile)
+	AddTarget(target Target)
+	Get(chatID int64) (Target, bool)
+	Deactivate(chatID int64)
+	GetAll() []int64
+	GetTargets() []Target
+	Count() int
+
+	// Журнал доставки и отзывы
+	RecordDelivery(article *news.Article, keywords []string)
+	RecordFeedback(chatID, userID int64, articleID, kind string, value int) error
+	Preferences() *news.Preferences
+
+	// Словари и интервальное повторение
+	RecordTerms(articleID string, translations map[string]string, chatIDs []int64)
+	SaveTerms(chatID int64, articleID string) (int, error)
+	NextDueCard(chatID int64, now time.Time) (*Card, error)
+	Card(id int64) (*Card, error)
+	UpdateCard(card *Card) error
+	VocabularyStats(chatID int64, now time.Time, limit int) (saved, due int, recent []Card, err error)
+	ChatsWithDueCards(now time.Time) (map[int64]int, error)
+
+	// Викторины
+	RecordQuizPoll(pollID string, chatID int64, articleID string, correctOption int)
+	RecordQuizAnswer(pollID string, userID int64, userName string, option int) error
+	Leaderboard(chatID int64, limit int) ([]Score, error)
+	UserScore(chatID, userID int64) (Score, error)
+
+	// Перенос данных и удаление по запросу подписчика
+	Export(chatID int64) (*Dump, error)
+	Import(dump *Dump) error
+	Forget(chatID, actorID int64) (Deleted, error)
+
+	// Ping проверяет, что хранилище доступно
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// AuditForget — действие журнала аудита: удаление данных чата по запросу
+const AuditForget = "forget"
+
+// Target описывает чат, в который доставляются новости: личный чат, группу или канал
+type Target struct {
+	ChatID   int64
+	Type     string // private, group, supergroup или channel
+	Title    string
+	ThreadID int  // тема форума, 0 — основной чат
+	Silent   bool // доставлять без звукового уведомления
+}
+
+// Profile — сведения о подписчике личного чата, полученные от Telegram
+type Profile struct {
+	ChatID       int64
+	Username     string
+	FirstName    string
+	LastName     string
+	LanguageCode string
+	ChatType     string
+	StartPayload string // параметр ссылки t.me/<бот>?start=..., по которой пришёл подписчик
+	LastSeen     time.Time
+}
+
+// Card — термин из личного словаря подписчика с состоянием интервального повторения
+type Card struct {
+	ID          int64
+	ChatID      int64
+	Term        string
+	Translation string
+	Ease        float64
+	Interval    int // интервал до следующего повторения в днях
+	Repetitions int // число успешных повторений подряд
+	DueAt       time.Time
+}
+
+// Score — результат участника викторин
+type Score struct {
+	UserID   int64
+	UserName string
+	Correct  int
+	Total    int
+}
+
+// Поддерживаемые хранилища
+const (
+	BackendSQLite   = "sqlite"
+	BackendPostgres = "postgres"
+	BackendMemory   = "memory"
+)
+
+// Поведение при недоступности основного хранилища
+const (
+	// FallbackFail — завершить запуск с ошибкой
+	FallbackFail = "fail"
+	// FallbackMemory — продолжить работу с хранилищем в памяти
+	FallbackMemory = "memory"
+)
+
+// Options задаёт выбор и параметры хранилища
+type Options struct {
+	Backend    string
+	SQLitePath string
+	// PostgresDSN — строка подключения к PostgreSQL для бэкенда postgres
+	PostgresDSN string
+	// SnapshotPath — JSON-файл, в который сохраняется хранилище в памяти; пустой — без снимков
+	SnapshotPath string
+	// Fallback определяет, что делать, если основное хранилище не открылось
+	Fallback string
+}
+
+// Open открывает хранилище согласно настройкам. Если основное хранилище недоступно
+// и разрешена деградация, возвращается хранилище в памяти, о чём пишется в лог.
+func Open(opts Options, logger *slog.Logger) (UserStore, error) {
+	var store UserStore
+	var err error
+
+	switch opts.Backend {
+	case BackendMemory:
+		return NewMemoryStore(opts.SnapshotPath, logger)
+	case BackendPostgres:
+		store, err = NewPostgresStore(opts.PostgresDSN, logger)
+	case BackendSQLite, "":
+		store, err = NewSQLiteStore(opts.SQLitePath, logger)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", opts.Backend)
+	}
+	if err == nil {
+		return store, nil
+	}
+
+	if opts.Fallback != FallbackMemory {
+		return nil, fmt.Errorf("failed to open %s storage: %w", opts.Backend, err)
+	}
+
+	logger.Warn("Failed to open storage, falling back to in-memory storage", "backend", opts.Backend, "error", err)
+	if opts.SnapshotPath == "" {
+		logger.Warn("In-memory storage has no snapshot file, subscribers will be lost on restart")
+	}
+	return NewMemoryStore(opts.SnapshotPath, logger)
+}
+
+// nullString превращает пустую строку в NULL, чтобы COALESCE в запросах
+// не затирал сохранённое значение пустым
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
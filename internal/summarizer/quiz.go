@@ -0,0 +1,151 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/news"
+	"github.com/andrei/goBot/internal/tracing"
+	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Ограничения Telegram для опросов-викторин
+const (
+	maxQuestionLength    = 300
+	maxOptionLength      = 100
+	maxExplanationLength = 200
+	minOptions           = 2
+	maxOptions           = 10
+)
+
+// QuizQuestion — вопрос с вариантами ответа для проверки понимания статьи
+type QuizQuestion struct {
+	Question    string   `json:"question"`
+	Options     []string `json:"options"`
+	Correct     int      `json:"correct"` // индекс правильного варианта в Options
+	Explanation string   `json:"explanation"`
+}
+
+// GenerateQuiz составляет вопросы с вариантами ответа по содержанию статьи
+func (s *Summarizer) GenerateQuiz(ctx context.Context, article *news.Article, questions int) (quiz []QuizQuestion, err error) {
+	ctx, span := tracer.Start(ctx, "summarizer.quiz", trace.WithAttributes(
+		attribute.String(logging.ArticleURL, article.URL),
+		attribute.Int("quiz.questions", questions),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	prompt := fmt.Sprintf(`Create %d multiple-choice questions that check reading comprehension of this technology article.
+The audience are Russian speakers learning English, so write questions and options in simple English.
+
+Rules:
+- Each question must be answerable from the article text alone
+- Give exactly 4 short options (under 100 characters each), only one of them correct
+- Add a one-sentence explanation of the correct answer (under 200 characters)
+- Do not use options like "all of the above" or "none of the above"
+
+Article Title: %s
+Article Content: %s
+
+Respond with JSON only, exactly in this format:
+{"questions": [{"question": "...", "options": ["...", "...", "...", "..."], "correct": 0, "explanation": "..."}]}
+where "correct" is the zero-based index of the correct option.`,
+		questions,
+		article.Title,
+		article.Content)
+
+	resp, err := s.complete(
+		ctx,
+		"quiz",
+		openai.ChatCompletionRequest{
+			Model: openai.GPT3Dot5Turbo,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: prompt,
+				},
+			},
+			ResponseFormat: &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+			},
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting quiz completion: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("empty quiz completion")
+	}
+
+	quiz, err = parseQuiz(resp.Choices[0].Message.Content)
+	if err != nil {
+		return nil, err
+	}
+	if len(quiz) > questions {
+		quiz = quiz[:questions]
+	}
+
+	return quiz, nil
+}
+
+// parseQuiz разбирает ответ модели и отбрасывает вопросы, которые нельзя отправить викториной
+func parseQuiz(response string) ([]QuizQuestion, error) {
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(response, "```json")
+	response = strings.TrimPrefix(response, "```")
+	response = strings.TrimSuffix(response, "```")
+
+	var parsed struct {
+		Questions []QuizQuestion `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
+		return nil, fmt.Errorf("error decoding quiz response: %w", err)
+	}
+
+	var quiz []QuizQuestion
+	for _, q := range parsed.Questions {
+		q.Question = strings.TrimSpace(q.Question)
+		q.Explanation = truncate(strings.TrimSpace(q.Explanation), maxExplanationLength)
+
+		if q.Question == "" || len([]rune(q.Question)) > maxQuestionLength {
+			continue
+		}
+		if len(q.Options) < minOptions || len(q.Options) > maxOptions {
+			continue
+		}
+		if q.Correct < 0 || q.Correct >= len(q.Options) {
+			continue
+		}
+
+		valid := true
+		for i, option := range q.Options {
+			q.Options[i] = strings.TrimSpace(option)
+			if q.Options[i] == "" || len([]rune(q.Options[i])) > maxOptionLength {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			quiz = append(quiz, q)
+		}
+	}
+
+	if len(quiz) == 0 {
+		return nil, fmt.Errorf("no valid quiz questions found in response: %s", response)
+	}
+
+	return quiz, nil
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,110 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/andrei/goBot/internal/news"
+	"github.com/andrei/goBot/internal/tracing"
+	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxRankDescription ограничивает описание статьи в запросе оценки, чтобы запрос
+// оставался дешёвым при любом размере списка
+const maxRankDescription = 300
+
+// rankTokensPerArticle — сколько токенов ответа отводится на оценку одной статьи
+const rankTokensPerArticle = 60
+
+// Rank оценивает статьи одним запросом по технической глубине и интересу для аудитории,
+// изучающей технологии и английский. Реализует news.Ranker.
+func (s *Summarizer) Rank(ctx context.Context, articles []*news.Article) (relevance []*news.Relevance, err error) {
+	ctx, span := tracer.Start(ctx, "summarizer.rank", trace.WithAttributes(attribute.Int("rank.articles", len(articles))))
+	defer func() { tracing.End(span, err) }()
+
+	var list strings.Builder
+	for i, article := range articles {
+		fmt.Fprintf(&list, "[%d] %s\n%s\n\n", i+1, article.Title, truncate(strings.TrimSpace(article.Description), maxRankDescription))
+	}
+
+	prompt := fmt.Sprintf(`Rate these technology news articles for a daily digest read by software engineers and IT students.
+
+For each article give two scores from 0 to 10:
+- depth: how substantive the engineering or technical content is (research, architecture, security incidents, new tools score high; deals, shopping guides, sponsored posts, rumors and product roundups score low)
+- relevance: how interesting and useful the story is for the audience
+
+Articles:
+%s
+Respond with JSON only, exactly in this format:
+{"articles": [{"id": 1, "depth": 7, "relevance": 8, "reason": "one short sentence"}]}
+with one entry for every article id.`, list.String())
+
+	resp, err := s.complete(
+		ctx,
+		"rank",
+		openai.ChatCompletionRequest{
+			Model: openai.GPT3Dot5Turbo,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: prompt,
+				},
+			},
+			ResponseFormat: &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+			},
+			MaxTokens:   rankTokensPerArticle*len(articles) + 50,
+			Temperature: 0,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting ranking completion: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("empty ranking completion")
+	}
+
+	return parseRanking(resp.Choices[0].Message.Content, len(articles))
+}
+
+// parseRanking разбирает ответ модели. Оценка статьи — среднее depth и relevance,
+// приведённое к диапазону от 0 до 1; статьи, которых нет в ответе, остаются без оценки.
+func parseRanking(response string, count int) ([]*news.Relevance, error) {
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(response, "```json")
+	response = strings.TrimPrefix(response, "```")
+	response = strings.TrimSuffix(response, "```")
+
+	var parsed struct {
+		Articles []struct {
+			ID        int     `json:"id"`
+			Depth     float64 `json:"depth"`
+			Relevance float64 `json:"relevance"`
+			Reason    string  `json:"reason"`
+		} `json:"articles"`
+	}
+	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
+		return nil, fmt.Errorf("error decoding ranking response: %w", err)
+	}
+
+	relevance := make([]*news.Relevance, count)
+	ranked := 0
+	for _, a := range parsed.Articles {
+		if a.ID < 1 || a.ID > count || relevance[a.ID-1] != nil {
+			continue
+		}
+		score := (max(0, min(10, a.Depth)) + max(0, min(10, a.Relevance))) / 20
+		relevance[a.ID-1] = &news.Relevance{Score: score, Reason: strings.TrimSpace(a.Reason)}
+		ranked++
+	}
+
+	if ranked == 0 {
+		return nil, fmt.Errorf("no article scores found in response: %s", response)
+	}
+
+	return relevance, nil
+}
@@ -3,29 +3,65 @@ package summarizer
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
+	"time"
 
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/metrics"
 	"github.com/andrei/goBot/internal/news"
+	"github.com/andrei/goBot/internal/tracing"
 	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/andrei/goBot/internal/summarizer")
+
 type Summary struct {
 	Summary     string
 	Keywords    []string
 	Translation map[string]string
+	Quiz        []QuizQuestion
 }
 
 type Summarizer struct {
 	client *openai.Client
+	logger *slog.Logger
 }
 
-func NewSummarizer(apiKey string) *Summarizer {
+func NewSummarizer(apiKey string, logger *slog.Logger) *Summarizer {
 	return &Summarizer{
 		client: openai.NewClient(apiKey),
+		logger: logger.With("component", "summarizer"),
+	}
+}
+
+// complete отправляет запрос к OpenAI и записывает длительность и расход токенов в метрики
+func (s *Summarizer) complete(ctx context.Context, operation string, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	start := time.Now()
+	resp, err := s.client.CreateChatCompletion(ctx, req)
+	metrics.ObserveOpenAI(operation, start, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, err)
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("openai.model", req.Model),
+		attribute.Int("openai.prompt_tokens", resp.Usage.PromptTokens),
+		attribute.Int("openai.completion_tokens", resp.Usage.CompletionTokens),
+	)
+	if err != nil {
+		s.logger.WarnContext(ctx, "OpenAI request failed", "operation", operation, "error", err)
+	} else {
+		s.logger.DebugContext(ctx, "OpenAI request completed", "operation", operation, "model", req.Model,
+			"prompt_tokens", resp.Usage.PromptTokens, "completion_tokens", resp.Usage.CompletionTokens, "duration", time.Since(start))
 	}
+
+	return resp, err
 }
 
-func (s *Summarizer) ProcessArticle(ctx context.Context, article *news.Article) (*Summary, error) {
+func (s *Summarizer) ProcessArticle(ctx context.Context, article *news.Article) (summary *Summary, err error) {
+	ctx, span := tracer.Start(ctx, "summarizer.summarize", trace.WithAttributes(attribute.String(logging.ArticleURL, article.URL)))
+	defer func() { tracing.End(span, err) }()
+
 	prompt := fmt.Sprintf(`Analyze this technology article and extract:
 
 1. 5 key technical terms/concepts that are actually used in the article.
@@ -48,8 +84,9 @@ Translations: term1: перевод1, term2: перевод2, term3: перев
 		article.Title, 
 		article.Content)
 
-	resp, err := s.client.CreateChatCompletion(
+	resp, err := s.complete(
 		ctx,
+		"summary",
 		openai.ChatCompletionRequest{
 			Model: openai.GPT3Dot5Turbo,
 			Messages: []openai.ChatCompletionMessage{
@@ -1,63 +1,96 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/metrics"
 	"github.com/andrei/goBot/internal/news"
+	"github.com/andrei/goBot/internal/storage"
 	"github.com/andrei/goBot/internal/summarizer"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/andrei/goBot/internal/telegram")
+
 type Bot struct {
 	api    *tgbotapi.BotAPI
-	users  *Users
-	logger *log.Logger
+	users  storage.UserStore
+	logger *slog.Logger
+
+	reviewsMu sync.Mutex
+	reviews   map[string]*review
+
+	// Пробный режим рассылки, см. SetDryRun
+	dryRunMu sync.Mutex
+	dryRun   *DryRunConfig
 }
 
-func NewBot(token string, logger *log.Logger) (*Bot, error) {
+func NewBot(token string, users storage.UserStore, logger *slog.Logger) (*Bot, error) {
 	api, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, fmt.Errorf("error creating telegram bot: %w", err)
 	}
 
 	return &Bot{
-		api:    api,
-		users:  NewUsers(),
-		logger: logger,
+		api:     api,
+		users:   users,
+		logger:  logger.With("component", "telegram"),
+		reviews: make(map[string]*review),
 	}, nil
 }
 
 func (b *Bot) Start() {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
-	updates := b.api.GetUpdatesChan(u)
+	updates := b.getUpdatesChan(u)
+
+	// Обновления, накопившиеся во время простоя, обрабатываются как обычные:
+	// среди них могут быть ответы редактора, ответы на опросы и изменения членства в чатах
+	b.logger.Info("Bot started and ready to receive messages")
 
-	// Пропускаем обновления, которые могли накопиться во время простоя бота
-	// Но проверяем, не была ли среди них команда /start
 	for update := range updates {
-		if update.Message != nil && update.Message.Command() == "start" {
-			b.handleStartCommand(update.Message)
+		b.touchUser(sender(update))
+
+		if update.MyChatMember != nil {
+			b.handleMyChatMember(update.MyChatMember)
+			continue
 		}
-		
-		// Проверяем, пуст ли канал
-		if len(updates) == 0 {
-			break
+
+		if update.CallbackQuery != nil {
+			b.handleCallbackQuery(update.CallbackQuery)
+			continue
 		}
-	}
 
-	b.logger.Println("Bot started and ready to receive messages")
+		if update.PollAnswer != nil {
+			b.handlePollAnswer(update.PollAnswer)
+			continue
+		}
 
-	for update := range updates {
 		if update.Message == nil {
 			continue
 		}
 
+		// Ответ редактора с новым текстом статьи
+		if b.handleModerationReply(update.Message) {
+			continue
+		}
+
 		// Обработка команд
 		switch update.Message.Command() {
 		case "start":
-			b.handleStartCommand(update.Message)
+			b.handleStartCommand(update.Message, update.ThreadID)
+		case "stop":
+			b.handleStopCommand(update.Message)
 		case "news":
 			// Отправляем сообщение о том, что обрабатываем запрос
 			msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Получаю последние технологические новости...")
@@ -67,26 +100,53 @@ func (b *Bot) Start() {
 			// просто отправляем уведомление
 			msg = tgbotapi.NewMessage(update.Message.Chat.ID, "Функция обработки новостей по запросу будет добавлена в следующем обновлении. Пока новости приходят по расписанию.")
 			b.api.Send(msg)
+		case "addchannel":
+			b.handleAddChannelCommand(update.Message)
+		case "removechannel":
+			b.handleRemoveChannelCommand(update.Message)
+		case "silent":
+			b.handleSilentCommand(update.Message)
+		case "vocab":
+			b.handleVocabCommand(update.Message)
+		case "score":
+			b.handleScoreCommand(update.Message)
+		case "forget":
+			b.handleForgetCommand(update.Message)
 		case "help":
 			b.handleHelpCommand(update.Message)
 		}
 	}
 }
 
-// handleStartCommand обрабатывает команду /start
-func (b *Bot) handleStartCommand(message *tgbotapi.Message) {
-	userID := message.Chat.ID
+// handleStartCommand обрабатывает команду /start.
+// В группе подписывает на новости весь чат, а в теме форума — только эту тему.
+func (b *Bot) handleStartCommand(message *tgbotapi.Message, threadID int) {
+	chat := message.Chat
+	if !chat.IsPrivate() {
+		b.handleGroupStart(message, threadID)
+		return
+	}
+
+	userID := chat.ID
 	userName := message.From.UserName
 	
-	// Добавляем пользователя в список подписчиков
-	b.users.Add(userID)
+	// Добавляем пользователя в список подписчиков вместе с профилем
+	// и параметром ссылки, по которой он пришёл
+	profile := userProfile(message.From)
+	profile.ChatType = chat.Type
+	profile.StartPayload = startPayload(message.CommandArguments())
+	b.users.Subscribe(profile)
 	
 	// Формируем приветственное сообщение
 	greeting := fmt.Sprintf("Привет, %s! 👋\n\n"+
 		"Я бот технологических новостей. Я буду присылать тебе интересные новости из мира технологий.\n\n"+
 		"<b>Доступные команды:</b>\n"+
 		"/start - Запустить бота\n"+
+		"/stop - Отписаться от новостей\n"+
 		"/news - Получить последние новости\n"+
+		"/vocab - Личный словарь терминов\n"+
+		"/score - Таблица лидеров викторин\n"+
+		"/addchannel - Подключить канал\n"+
 		"/help - Показать помощь\n\n"+
 		"Жди первую новость или используй команду /news, чтобы получить её сейчас!",
 		userName)
@@ -95,16 +155,69 @@ func (b *Bot) handleStartCommand(message *tgbotapi.Message) {
 	msg.ParseMode = "HTML"
 	b.api.Send(msg)
 	
-	b.logger.Printf("New user subscribed: %s (ID: %d)", userName, userID)
+	b.logger.Info("New user subscribed", "chat_id", userID, "name", userName, "payload", profile.StartPayload)
+}
+
+// maxStartPayload — максимальная длина параметра ссылки /start по ограничениям Telegram
+const maxStartPayload = 64
+
+// startPayload возвращает параметр ссылки t.me/<бот>?start=..., обрезанный до допустимой длины
+func startPayload(args string) string {
+	payload := strings.TrimSpace(args)
+	if len(payload) > maxStartPayload {
+		payload = payload[:maxStartPayload]
+	}
+
+	return payload
+}
+
+// userProfile собирает профиль подписчика личного чата из данных Telegram
+func userProfile(user *tgbotapi.User) storage.Profile {
+	return storage.Profile{
+		ChatID:       user.ID,
+		Username:     user.UserName,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		LanguageCode: user.LanguageCode,
+		ChatType:     "private",
+		LastSeen:     time.Now(),
+	}
+}
+
+// sender возвращает автора обновления, включая ответы на викторины
+func sender(update update) *tgbotapi.User {
+	if update.PollAnswer != nil {
+		return &update.PollAnswer.User
+	}
+
+	return update.SentFrom()
+}
+
+// touchUser обновляет профиль и время последнего взаимодействия подписчика.
+// Для тех, кто не подписан на бота в личном чате, ничего не сохраняется.
+func (b *Bot) touchUser(user *tgbotapi.User) {
+	if user == nil || user.IsBot {
+		return
+	}
+
+	b.users.Touch(userProfile(user))
 }
 
 // handleHelpCommand обрабатывает команду /help
 func (b *Bot) handleHelpCommand(message *tgbotapi.Message) {
 	helpText := "<b>Помощь по использованию бота:</b>\n\n" +
-		"Этот бот отправляет технологические новости по расписанию.\n\n" +
+		"Этот бот отправляет технологические новости по расписанию.\n" +
+		"Его можно добавить в группу (команда /start в нужной теме форума) или в канал.\n\n" +
 		"<b>Доступные команды:</b>\n" +
 		"/start - Запустить бота и подписаться на новости\n" +
+		"/stop - Отписаться от новостей\n" +
 		"/news - Получить последние новости сейчас\n" +
+		"/vocab - Личный словарь терминов и повторение карточек\n" +
+		"/score - Таблица лидеров викторин по статьям\n" +
+		"/addchannel @канал - Присылать новости в канал, где бот администратор\n" +
+		"/removechannel @канал - Отключить рассылку в канал\n" +
+		"/silent on|off - Присылать новости в этот чат без звука\n" +
+		"/forget - Удалить все данные этого чата\n" +
 		"/help - Показать эту помощь\n\n" +
 		"Если у вас возникли проблемы, пожалуйста, свяжитесь с разработчиком."
 	
@@ -113,25 +226,157 @@ func (b *Bot) handleHelpCommand(message *tgbotapi.Message) {
 	b.api.Send(msg)
 }
 
-func (b *Bot) SendArticleSummary(article *news.Article, summary *summarizer.Summary) error {
-	message := b.formatMessage(article, summary)
-	
-	for _, userID := range b.users.GetAll() {
-		msg := tgbotapi.NewMessage(userID, message)
-		msg.ParseMode = "HTML"
-		msg.DisableWebPagePreview = false
-
-		_, err := b.api.Send(msg)
-		if err != nil {
-			b.logger.Printf("Error sending message to user %d: %v", userID, err)
-			continue
+// handleCallbackQuery обрабатывает нажатия на inline-кнопки
+func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
+	prefix, _, _ := strings.Cut(query.Data, ":")
+
+	switch prefix {
+	case moderationCallbackPrefix:
+		b.handleModerationCallback(query)
+	case feedbackCallbackPrefix:
+		b.handleFeedbackCallback(query)
+	case vocabularyCallbackPrefix:
+		b.handleVocabularyCallback(query)
+	case forgetCallbackPrefix:
+		b.handleForgetCallback(query)
+	default:
+		b.answerCallback(query, "")
+	}
+}
+
+// answerCallback убирает индикатор загрузки на кнопке и при необходимости показывает уведомление
+func (b *Bot) answerCallback(query *tgbotapi.CallbackQuery, text string) {
+	if _, err := b.api.Request(tgbotapi.NewCallback(query.ID, text)); err != nil {
+		b.logger.Warn("Error answering callback query", "query_id", query.ID, "error", err)
+	}
+}
+
+func (b *Bot) SendArticleSummary(ctx context.Context, article *news.Article, summary *summarizer.Summary) error {
+	return b.broadcast(ctx, article, summary, render(ctx, article, summary))
+}
+
+// sendBatchSize — число получателей в одном спане отправки: по спанам видно, какая
+// часть рассылки шла медленно, без отдельного спана на каждый чат
+const sendBatchSize = 30
+
+// render оформляет сообщение о статье, записывая этот этап в трассу
+func render(ctx context.Context, article *news.Article, summary *summarizer.Summary) string {
+	_, span := tracer.Start(ctx, "telegram.render", trace.WithAttributes(attribute.String(logging.ArticleURL, article.URL)))
+	defer span.End()
+
+	text := FormatMessage(article, summary)
+	span.SetAttributes(attribute.Int("message.length", len(text)))
+
+	return text
+}
+
+// broadcast рассылает готовое сообщение о статье всем подписчикам, группам и каналам,
+// прикрепляет к нему кнопки отзыва и сохранения терминов, а затем отправляет викторину по статье
+func (b *Bot) broadcast(ctx context.Context, article *news.Article, summary *summarizer.Summary, message string) error {
+	ctx = logging.With(ctx, logging.ArticleURL, article.URL)
+	ctx, span := tracer.Start(ctx, "telegram.broadcast", trace.WithAttributes(attribute.String(logging.ArticleURL, article.URL)))
+	defer span.End()
+
+	var keywords []string
+	if summary != nil {
+		keywords = summary.Keywords
+	}
+
+	// В пробном режиме сообщение получают только чаты из списка разрешённых,
+	// а остальные получатели записываются вместе с сообщением
+	dryRun := b.dryRunConfig()
+	targets := b.users.GetTargets()
+	var skipped []storage.Target
+	if dryRun != nil {
+		var allowed []storage.Target
+		for _, target := range targets {
+			if dryRun.allows(target.ChatID) {
+				allowed = append(allowed, target)
+			} else {
+				skipped = append(skipped, target)
+			}
 		}
+		targets = allowed
+		span.SetAttributes(attribute.Bool("dry_run", true), attribute.Int("dry_run.written", len(skipped)))
+		b.writeDryRun(ctx, dryRun, article, summary, message, skipped)
+		b.logger.InfoContext(ctx, "Dry run: message written instead of sending", "written", len(skipped), "allowed", len(targets))
+
+		// Журнал доставки влияет на выбор следующих статей, поэтому без настоящей отправки он не пополняется
+		if len(targets) == 0 {
+			return nil
+		}
+	}
+
+	b.users.RecordDelivery(article, keywords)
+
+	keyboard := feedbackKeyboard(article.ID())
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, vocabularyRow(article.ID()))
+
+	// Термины попадают в историю подписчиков, получивших статью в личном чате
+	var recipients []int64
+	for start := 0; start < len(targets); start += sendBatchSize {
+		batch := targets[start:min(start+sendBatchSize, len(targets))]
+		recipients = append(recipients, b.sendBatch(ctx, batch, article, summary, message, keyboard)...)
 	}
+	span.SetAttributes(attribute.Int("targets", len(targets)))
+
+	b.users.RecordTerms(article.ID(), deliveredTerms(summary), recipients)
+	b.logger.InfoContext(ctx, "Article broadcast", "recipients", len(targets))
 
 	return nil
 }
 
-func (b *Bot) formatMessage(article *news.Article, summary *summarizer.Summary) string {
+// sendBatch отправляет сообщение и викторину части получателей и возвращает личные чаты,
+// в которые сообщение доставлено
+func (b *Bot) sendBatch(ctx context.Context, batch []storage.Target, article *news.Article, summary *summarizer.Summary, message string, keyboard tgbotapi.InlineKeyboardMarkup) []int64 {
+	ctx, span := tracer.Start(ctx, "telegram.send_batch", trace.WithAttributes(attribute.Int("batch.size", len(batch))))
+	defer span.End()
+
+	var recipients []int64
+	failed := 0
+	for _, target := range batch {
+		if err := b.sendToTarget(target, message, keyboard); err != nil {
+			b.logger.ErrorContext(ctx, "Error sending message", "chat_type", target.Type, logging.ChatID, target.ChatID, "error", err)
+			metrics.MessageFailed(target.Type, errorClass(err))
+			span.AddEvent("send failed", trace.WithAttributes(attribute.Int64(logging.ChatID, target.ChatID), attribute.String("reason", errorClass(err))))
+			failed++
+			continue
+		}
+		metrics.MessageSent(target.Type)
+		if target.Type == "private" {
+			recipients = append(recipients, target.ChatID)
+		}
+		if summary != nil && len(summary.Quiz) > 0 {
+			b.sendQuiz(ctx, target, article.ID(), summary.Quiz)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("batch.sent", len(batch)-failed), attribute.Int("batch.failed", failed))
+	if failed == len(batch) {
+		span.SetStatus(codes.Error, "all messages in batch failed")
+	}
+
+	return recipients
+}
+
+// deliveredTerms возвращает термины, которые попадают в сообщение: только с переводом
+func deliveredTerms(summary *summarizer.Summary) map[string]string {
+	terms := make(map[string]string)
+	if summary == nil {
+		return terms
+	}
+
+	for _, keyword := range summary.Keywords {
+		if translation, ok := summary.Translation[keyword]; ok {
+			terms[keyword] = translation
+		}
+	}
+
+	return terms
+}
+
+// FormatMessage оформляет сообщение о статье в HTML в том виде, в котором его получают подписчики
+func FormatMessage(article *news.Article, summary *summarizer.Summary) string {
 	var sb strings.Builder
 
 	// Заголовок статьи
@@ -179,7 +424,23 @@ func (b *Bot) formatMessage(article *news.Article, summary *summarizer.Summary)
 	return sb.String()
 }
 
+// Ping проверяет, что Telegram Bot API доступен и токен действителен
+func (b *Bot) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := b.api.GetMe()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Users возвращает объект пользователей
-func (b *Bot) Users() *Users {
+func (b *Bot) Users() storage.UserStore {
 	return b.users
 }
\ No newline at end of file
@@ -0,0 +1,280 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/storage"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sendToTarget отправляет HTML-сообщение получателю с учётом его настроек.
+// tgbotapi не поддерживает message_thread_id, поэтому запрос собирается вручную.
+func (b *Bot) sendToTarget(target storage.Target, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", target.ChatID)
+	params.AddNonEmpty("text", text)
+	params.AddNonEmpty("parse_mode", "HTML")
+	params.AddNonZero("message_thread_id", target.ThreadID)
+	params.AddBool("disable_notification", target.Silent)
+	if len(keyboard.InlineKeyboard) > 0 {
+		if err := params.AddInterface("reply_markup", keyboard); err != nil {
+			return err
+		}
+	}
+
+	_, err := b.api.MakeRequest("sendMessage", params)
+	return err
+}
+
+// errorClass относит ошибку отправки к классу для метрик: blocked — бот заблокирован
+// или удалён из чата, rate_limited — превышен лимит Telegram, network — ответ не получен
+func errorClass(err error) string {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return "network"
+	}
+
+	switch apiErr.Code {
+	case http.StatusForbidden:
+		return "blocked"
+	case http.StatusTooManyRequests:
+		return "rate_limited"
+	case http.StatusBadRequest:
+		return "bad_request"
+	default:
+		return "api_error"
+	}
+}
+
+// handleGroupStart подписывает группу (или тему форума) на рассылку. Чат получает
+// новости только в одну тему: /start из другой темы не переносит рассылку молча.
+func (b *Bot) handleGroupStart(message *tgbotapi.Message, threadID int) {
+	chat := message.Chat
+	if !b.isChatAdmin(chat.ID, message.From) {
+		b.replyInChat(message, "Включить рассылку в группе могут только администраторы.")
+		return
+	}
+
+	target := storage.Target{
+		ChatID:   chat.ID,
+		Type:     chat.Type,
+		Title:    chat.Title,
+		ThreadID: threadID,
+	}
+	// Повторный /start не должен сбрасывать настройки группы
+	if existing, ok := b.users.Get(chat.ID); ok {
+		// Чат, зарегистрированный при добавлении бота, ещё не привязан к теме
+		if existing.ThreadID != 0 && existing.ThreadID != threadID {
+			b.replyInChat(message, "Рассылка в этом чате уже идёт в другую тему. Чтобы перенести её сюда, отправьте /stop, а затем /start в этой теме.")
+			return
+		}
+		target.Silent = existing.Silent
+	}
+	b.users.AddTarget(target)
+
+	text := fmt.Sprintf("Привет, %s! 👋\n\nБуду присылать технологические новости в этот чат.", chat.Title)
+	if threadID != 0 {
+		text = fmt.Sprintf("Привет, %s! 👋\n\nБуду присылать технологические новости в эту тему.", chat.Title)
+	}
+	b.replyInChat(message, text)
+
+	b.logger.Info("Group subscribed", logging.ChatID, chat.ID, "title", chat.Title, "thread_id", threadID)
+}
+
+// handleStopCommand отключает рассылку в текущий чат
+func (b *Bot) handleStopCommand(message *tgbotapi.Message) {
+	if !message.Chat.IsPrivate() && !b.isChatAdmin(message.Chat.ID, message.From) {
+		b.replyInChat(message, "Отключить рассылку в группе могут только администраторы.")
+		return
+	}
+
+	b.users.Deactivate(message.Chat.ID)
+	b.replyInChat(message, "Рассылка отключена. Чтобы снова получать новости, отправьте /start.")
+}
+
+// handleSilentCommand включает или выключает доставку без звука для текущего чата
+func (b *Bot) handleSilentCommand(message *tgbotapi.Message) {
+	target, ok := b.users.Get(message.Chat.ID)
+	if !ok {
+		b.replyInChat(message, "Этот чат не подписан на новости. Отправьте /start.")
+		return
+	}
+	if !message.Chat.IsPrivate() && !b.isChatAdmin(message.Chat.ID, message.From) {
+		b.replyInChat(message, "Менять настройки группы могут только администраторы.")
+		return
+	}
+
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "on":
+		target.Silent = true
+	case "off":
+		target.Silent = false
+	default:
+		b.replyInChat(message, "Использование: /silent on или /silent off")
+		return
+	}
+
+	b.users.AddTarget(target)
+	if target.Silent {
+		b.replyInChat(message, "🔕 Новости будут приходить без звука.")
+	} else {
+		b.replyInChat(message, "🔔 Новости будут приходить со звуком.")
+	}
+}
+
+// handleAddChannelCommand подключает канал по @username или ID.
+// Бот должен быть администратором канала с правом публикации, а автор команды — его администратором.
+func (b *Bot) handleAddChannelCommand(message *tgbotapi.Message) {
+	chat, ok := b.resolveManagedChat(message)
+	if !ok {
+		return
+	}
+
+	member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chat.ID, UserID: b.api.Self.ID},
+	})
+	if err != nil {
+		b.replyInChat(message, "Не удалось проверить права бота в этом чате. Добавьте бота в администраторы.")
+		b.logger.Error("Error checking bot membership", logging.ChatID, chat.ID, "error", err)
+		return
+	}
+
+	canPost := member.IsCreator() || member.IsAdministrator()
+	if chat.IsChannel() {
+		canPost = member.IsAdministrator() && member.CanPostMessages
+	}
+	if !canPost {
+		b.replyInChat(message, "Бот должен быть администратором с правом публикации сообщений.")
+		return
+	}
+
+	target := storage.Target{
+		ChatID: chat.ID,
+		Type:   chat.Type,
+		Title:  chat.Title,
+	}
+	// Необязательный второй аргумент: /addchannel @канал silent
+	if args := strings.Fields(message.CommandArguments()); len(args) > 1 && strings.EqualFold(args[1], "silent") {
+		target.Silent = true
+	}
+	b.users.AddTarget(target)
+
+	b.replyInChat(message, fmt.Sprintf("✅ Новости будут публиковаться в «%s».", chat.Title))
+	b.logger.Info("Chat registered", logging.ChatID, chat.ID, "title", chat.Title, "user_id", message.From.ID)
+}
+
+// handleRemoveChannelCommand отключает рассылку в канал
+func (b *Bot) handleRemoveChannelCommand(message *tgbotapi.Message) {
+	chat, ok := b.resolveManagedChat(message)
+	if !ok {
+		return
+	}
+
+	b.users.Deactivate(chat.ID)
+	b.replyInChat(message, fmt.Sprintf("Рассылка в «%s» отключена.", chat.Title))
+}
+
+// resolveManagedChat находит чат из аргумента команды и проверяет, что автор команды — его администратор
+func (b *Bot) resolveManagedChat(message *tgbotapi.Message) (tgbotapi.Chat, bool) {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		b.replyInChat(message, fmt.Sprintf("Использование: /%s @канал или /%s -100123456789", message.Command(), message.Command()))
+		return tgbotapi.Chat{}, false
+	}
+
+	config := tgbotapi.ChatConfig{SuperGroupUsername: args[0]}
+	if id, err := strconv.ParseInt(args[0], 10, 64); err == nil {
+		config = tgbotapi.ChatConfig{ChatID: id}
+	} else if !strings.HasPrefix(args[0], "@") {
+		config.SuperGroupUsername = "@" + args[0]
+	}
+
+	chat, err := b.api.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: config})
+	if err != nil {
+		b.replyInChat(message, "Чат не найден. Убедитесь, что бот добавлен в него.")
+		b.logger.Warn("Error resolving chat", "chat", args[0], "error", err)
+		return tgbotapi.Chat{}, false
+	}
+	if chat.IsPrivate() {
+		b.replyInChat(message, "Укажите канал или группу.")
+		return tgbotapi.Chat{}, false
+	}
+
+	if !b.isChatAdmin(chat.ID, message.From) {
+		b.replyInChat(message, "Управлять рассылкой в этом чате могут только его администраторы.")
+		return tgbotapi.Chat{}, false
+	}
+
+	return chat, true
+}
+
+// handleMyChatMember отслеживает добавление бота в чаты и удаление из них
+func (b *Bot) handleMyChatMember(updated *tgbotapi.ChatMemberUpdated) {
+	chat := updated.Chat
+	member := updated.NewChatMember
+
+	// Бота удалили из чата или пользователь заблокировал бота
+	if member.HasLeft() || member.WasKicked() {
+		b.users.Deactivate(chat.ID)
+		b.logger.Info("Bot removed from chat", "chat_type", chat.Type, logging.ChatID, chat.ID, "title", chat.Title)
+		return
+	}
+
+	if chat.IsPrivate() {
+		return
+	}
+
+	// В канал можно публиковать только с правами администратора
+	if chat.IsChannel() && !(member.IsAdministrator() && member.CanPostMessages) {
+		b.logger.Warn("Bot added to channel without permission to post", logging.ChatID, chat.ID, "title", chat.Title)
+		return
+	}
+
+	if existing, ok := b.users.Get(chat.ID); ok {
+		// Чат уже зарегистрирован, например изменились права бота
+		existing.Title = chat.Title
+		b.users.AddTarget(existing)
+		return
+	}
+
+	b.users.AddTarget(storage.Target{
+		ChatID: chat.ID,
+		Type:   chat.Type,
+		Title:  chat.Title,
+	})
+	b.logger.Info("Bot added to chat", "chat_type", chat.Type, logging.ChatID, chat.ID, "title", chat.Title, "user_id", updated.From.ID)
+}
+
+// isChatAdmin проверяет, является ли пользователь администратором чата
+func (b *Bot) isChatAdmin(chatID int64, user *tgbotapi.User) bool {
+	if user == nil {
+		return false
+	}
+
+	member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: user.ID},
+	})
+	if err != nil {
+		b.logger.Error("Error checking admin rights", "user_id", user.ID, logging.ChatID, chatID, "error", err)
+		return false
+	}
+
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// replyInChat отвечает на сообщение; ответ попадает в ту же тему форума, что и исходное сообщение
+func (b *Bot) replyInChat(message *tgbotapi.Message, text string) {
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	if !message.Chat.IsPrivate() {
+		msg.ReplyToMessageID = message.MessageID
+	}
+
+	if _, err := b.api.Send(msg); err != nil {
+		b.logger.Error("Error replying in chat", logging.ChatID, message.Chat.ID, "error", err)
+	}
+}
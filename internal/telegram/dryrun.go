@@ -0,0 +1,102 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andrei/goBot/internal/news"
+	"github.com/andrei/goBot/internal/storage"
+	"github.com/andrei/goBot/internal/summarizer"
+)
+
+// DryRunConfig задаёт пробный режим рассылки: сообщения не отправляются, а записываются
+// в Output вместе со списком получателей. Чатам из Allowlist рассылка отправляется по-настоящему.
+type DryRunConfig struct {
+	Output    string // файл, в конец которого дописываются сообщения; пустое значение — stdout
+	Allowlist []int64
+}
+
+// SetDryRun включает пробный режим рассылки; nil выключает его.
+// Ответы на команды и черновики модерации отправляются как обычно.
+func (b *Bot) SetDryRun(cfg *DryRunConfig) {
+	b.dryRunMu.Lock()
+	defer b.dryRunMu.Unlock()
+
+	b.dryRun = cfg
+}
+
+func (b *Bot) dryRunConfig() *DryRunConfig {
+	b.dryRunMu.Lock()
+	defer b.dryRunMu.Unlock()
+
+	return b.dryRun
+}
+
+// allows сообщает, отправляется ли рассылка в чат в пробном режиме
+func (d *DryRunConfig) allows(chatID int64) bool {
+	for _, id := range d.Allowlist {
+		if id == chatID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeDryRun записывает сообщение, которое получили бы чаты skipped, если бы не пробный режим
+func (b *Bot) writeDryRun(ctx context.Context, cfg *DryRunConfig, article *news.Article, summary *summarizer.Summary, message string, skipped []storage.Target) {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("=== Dry run %s: %s\n", time.Now().Format("2006-01-02 15:04:05"), article.URL))
+	sb.WriteString(fmt.Sprintf("Recipients (%d):\n", len(skipped)))
+	for _, target := range skipped {
+		sb.WriteString(fmt.Sprintf("  %s %d", target.Type, target.ChatID))
+		if target.Title != "" {
+			sb.WriteString(fmt.Sprintf(" %q", target.Title))
+		}
+		if target.ThreadID != 0 {
+			sb.WriteString(fmt.Sprintf(" thread %d", target.ThreadID))
+		}
+		if target.Silent {
+			sb.WriteString(" silent")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("Message:\n")
+	sb.WriteString(message)
+	sb.WriteString("\n")
+
+	if summary != nil {
+		for i, question := range summary.Quiz {
+			sb.WriteString(fmt.Sprintf("Quiz %d: %s\n", i+1, question.Question))
+			for j, option := range question.Options {
+				mark := " "
+				if j == question.Correct {
+					mark = "*"
+				}
+				sb.WriteString(fmt.Sprintf("  %s %s\n", mark, option))
+			}
+		}
+	}
+	sb.WriteString("=== End of dry run\n\n")
+
+	var w io.Writer = os.Stdout
+	if cfg.Output != "" {
+		file, err := os.OpenFile(cfg.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			b.logger.ErrorContext(ctx, "Error opening dry run output", "path", cfg.Output, "error", err)
+			return
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		b.logger.ErrorContext(ctx, "Error writing dry run output", "error", err)
+	}
+}
@@ -0,0 +1,104 @@
+package telegram
+
+import (
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// entitiesHTML восстанавливает HTML-разметку сообщения по его entities: клиент Telegram
+// передаёт жирный текст, курсив и ссылки не тегами, а отдельным списком. Без entities
+// текст возвращается как есть, чтобы редактор мог прислать разметку тегами.
+func entitiesHTML(text string, entities []tgbotapi.MessageEntity) string {
+	var formatting []tgbotapi.MessageEntity
+	for _, entity := range entities {
+		if _, _, ok := entityTags(entity); ok {
+			formatting = append(formatting, entity)
+		}
+	}
+	if len(formatting) == 0 {
+		return text
+	}
+
+	// Внешняя сущность открывается раньше вложенной: при равном начале — более длинная
+	sort.SliceStable(formatting, func(i, j int) bool {
+		if formatting[i].Offset != formatting[j].Offset {
+			return formatting[i].Offset < formatting[j].Offset
+		}
+		return formatting[i].Length > formatting[j].Length
+	})
+
+	var b strings.Builder
+	var open []tgbotapi.MessageEntity
+	next := 0
+	offset := 0 // смещения entities считаются в единицах UTF-16
+	emit := func() {
+		for len(open) > 0 {
+			last := open[len(open)-1]
+			if last.Offset+last.Length > offset {
+				break
+			}
+			_, closing, _ := entityTags(last)
+			b.WriteString(closing)
+			open = open[:len(open)-1]
+		}
+		for next < len(formatting) && formatting[next].Offset <= offset {
+			opening, _, _ := entityTags(formatting[next])
+			b.WriteString(opening)
+			open = append(open, formatting[next])
+			next++
+		}
+	}
+
+	for _, r := range text {
+		emit()
+		b.WriteString(html.EscapeString(string(r)))
+		offset += len(utf16.Encode([]rune{r}))
+	}
+	emit()
+	for i := len(open) - 1; i >= 0; i-- {
+		_, closing, _ := entityTags(open[i])
+		b.WriteString(closing)
+	}
+
+	return b.String()
+}
+
+// entityHTMLTags — HTML-теги сущностей форматирования без параметров
+var entityHTMLTags = map[string]string{
+	"bold":          "b",
+	"italic":        "i",
+	"underline":     "u",
+	"strikethrough": "s",
+	"spoiler":       "tg-spoiler",
+	"code":          "code",
+	"blockquote":    "blockquote",
+}
+
+// entityTags возвращает открывающий и закрывающий теги сущности; ok равен false для
+// сущностей, которые Telegram распознаёт в тексте сам: упоминаний, ссылок, хэштегов
+func entityTags(entity tgbotapi.MessageEntity) (opening, closing string, ok bool) {
+	if tag, ok := entityHTMLTags[entity.Type]; ok {
+		return "<" + tag + ">", "</" + tag + ">", true
+	}
+
+	switch entity.Type {
+	case "pre":
+		if entity.Language != "" {
+			return `<pre><code class="language-` + html.EscapeString(entity.Language) + `">`, "</code></pre>", true
+		}
+		return "<pre>", "</pre>", true
+	case "text_link":
+		return `<a href="` + html.EscapeString(entity.URL) + `">`, "</a>", true
+	case "text_mention":
+		if entity.User != nil {
+			return `<a href="tg://user?id=` + strconv.FormatInt(entity.User.ID, 10) + `">`, "</a>", true
+		}
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,69 @@
+package telegram
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestEntitiesHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities []tgbotapi.MessageEntity
+		want     string
+	}{
+		{
+			name: "no entities keeps HTML tags typed by the editor",
+			text: "<b>Go</b> 1.25",
+			want: "<b>Go</b> 1.25",
+		},
+		{
+			name:     "bold and italic",
+			text:     "Go 1.25 is out",
+			entities: []tgbotapi.MessageEntity{{Type: "bold", Offset: 0, Length: 7}, {Type: "italic", Offset: 11, Length: 3}},
+			want:     "<b>Go 1.25</b> is <i>out</i>",
+		},
+		{
+			name: "nested entities",
+			text: "read the release notes",
+			entities: []tgbotapi.MessageEntity{
+				{Type: "bold", Offset: 0, Length: 22},
+				{Type: "text_link", Offset: 9, Length: 13, URL: "https://go.dev/doc/go1.25?a=1&b=2"},
+			},
+			want: `<b>read the <a href="https://go.dev/doc/go1.25?a=1&amp;b=2">release notes</a></b>`,
+		},
+		{
+			name:     "plain text is escaped",
+			text:     "a < b & c",
+			entities: []tgbotapi.MessageEntity{{Type: "code", Offset: 0, Length: 1}},
+			want:     "<code>a</code> &lt; b &amp; c",
+		},
+		{
+			name:     "offsets count UTF-16 code units",
+			text:     "🚀 Запуск ракеты",
+			entities: []tgbotapi.MessageEntity{{Type: "bold", Offset: 3, Length: 6}},
+			want:     "🚀 <b>Запуск</b> ракеты",
+		},
+		{
+			name:     "entities Telegram detects itself stay plain text",
+			text:     "see https://go.dev #golang",
+			entities: []tgbotapi.MessageEntity{{Type: "url", Offset: 4, Length: 14}, {Type: "hashtag", Offset: 19, Length: 7}},
+			want:     "see https://go.dev #golang",
+		},
+		{
+			name:     "pre with language",
+			text:     "fmt.Println(1)",
+			entities: []tgbotapi.MessageEntity{{Type: "pre", Offset: 0, Length: 14, Language: "go"}},
+			want:     `<pre><code class="language-go">fmt.Println(1)</code></pre>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entitiesHTML(tt.text, tt.entities); got != tt.want {
+				t.Errorf("entitiesHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"strings"
+
+	"github.com/andrei/goBot/internal/storage"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const feedbackCallbackPrefix = "fb"
+
+// feedbackActions сопоставляет кнопку с видом и значением отзыва
+var feedbackActions = map[string]struct {
+	kind  string
+	value int
+	reply string
+}{
+	"up":   {storage.FeedbackVote, 1, "👍 Спасибо за отзыв!"},
+	"down": {storage.FeedbackVote, -1, "👎 Спасибо, учтём!"},
+	"more": {storage.FeedbackPreference, 1, "Будем присылать больше похожих новостей"},
+	"less": {storage.FeedbackPreference, -1, "Будем присылать меньше похожих новостей"},
+}
+
+// feedbackKeyboard возвращает кнопки отзыва, прикрепляемые к каждой статье
+func feedbackKeyboard(articleID string) tgbotapi.InlineKeyboardMarkup {
+	data := func(action string) string {
+		return feedbackCallbackPrefix + ":" + action + ":" + articleID
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👍", data("up")),
+			tgbotapi.NewInlineKeyboardButtonData("👎", data("down")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Больше такого", data("more")),
+			tgbotapi.NewInlineKeyboardButtonData("Меньше такого", data("less")),
+		),
+	)
+}
+
+// handleFeedbackCallback сохраняет отзыв подписчика о статье
+func (b *Bot) handleFeedbackCallback(query *tgbotapi.CallbackQuery) {
+	parts := strings.SplitN(query.Data, ":", 3)
+	if len(parts) != 3 || query.Message == nil || query.From == nil {
+		b.answerCallback(query, "")
+		return
+	}
+
+	action, ok := feedbackActions[parts[1]]
+	if !ok {
+		b.answerCallback(query, "")
+		return
+	}
+
+	if err := b.users.RecordFeedback(query.Message.Chat.ID, query.From.ID, parts[2], action.kind, action.value); err != nil {
+		b.logger.Error("Error recording feedback", "user_id", query.From.ID, "error", err)
+		b.answerCallback(query, "Не удалось сохранить отзыв, попробуйте позже")
+		return
+	}
+
+	b.answerCallback(query, action.reply)
+}
@@ -0,0 +1,266 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/storage"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const vocabularyCallbackPrefix = "voc"
+
+// Оценки качества ответа по шкале SM-2, доступные на карточке
+var flashcardGrades = []struct {
+	label   string
+	quality int
+}{
+	{"❌ Не помню", 1},
+	{"😐 Трудно", 3},
+	{"🙂 Хорошо", 4},
+	{"😎 Легко", 5},
+}
+
+// scheduleReview пересчитывает состояние карточки по алгоритму SM-2.
+// quality — оценка ответа от 0 (полный провал) до 5 (идеальный ответ).
+func scheduleReview(card *storage.Card, quality int, now time.Time) {
+	if quality < 3 {
+		// Термин забыт: начинаем повторение заново
+		card.Repetitions = 0
+		card.Interval = 1
+	} else {
+		switch card.Repetitions {
+		case 0:
+			card.Interval = 1
+		case 1:
+			card.Interval = 6
+		default:
+			card.Interval = int(math.Round(float64(card.Interval) * card.Ease))
+		}
+		card.Repetitions++
+	}
+
+	q := float64(5 - quality)
+	card.Ease += 0.1 - q*(0.08+q*0.02)
+	if card.Ease < 1.3 {
+		card.Ease = 1.3
+	}
+
+	card.DueAt = now.AddDate(0, 0, card.Interval)
+}
+
+// vocabularyRow возвращает кнопку сохранения терминов статьи в личный словарь
+func vocabularyRow(articleID string) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📚 Сохранить термины", vocabularyCallbackPrefix+":save:"+articleID),
+	)
+}
+
+// handleVocabCommand показывает личный словарь подписчика
+func (b *Bot) handleVocabCommand(message *tgbotapi.Message) {
+	if !message.Chat.IsPrivate() {
+		b.replyInChat(message, "Словарь доступен в личном чате с ботом.")
+		return
+	}
+
+	saved, due, recent, err := b.users.VocabularyStats(message.Chat.ID, time.Now(), 10)
+	if err != nil {
+		b.logger.Error("Error retrieving vocabulary", logging.ChatID, message.Chat.ID, "error", err)
+		b.replyInChat(message, "Не удалось загрузить словарь, попробуйте позже.")
+		return
+	}
+
+	if saved == 0 {
+		b.replyInChat(message, "📚 Ваш словарь пока пуст. Нажмите «Сохранить термины» под статьёй, чтобы добавить термины для повторения.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<b>📚 Ваш словарь:</b> %d терминов, к повторению: %d\n\n", saved, due))
+	sb.WriteString("<b>Последние термины:</b>\n")
+	for _, card := range recent {
+		sb.WriteString(fmt.Sprintf("• %s — %s\n", html.EscapeString(card.Term), html.EscapeString(card.Translation)))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, sb.String())
+	msg.ParseMode = "HTML"
+	if due > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🧠 Повторить", vocabularyCallbackPrefix+":review"),
+		))
+	}
+
+	if _, err := b.api.Send(msg); err != nil {
+		b.logger.Error("Error sending vocabulary", logging.ChatID, message.Chat.ID, "error", err)
+	}
+}
+
+// SendDueReviews предлагает повторить термины всем подписчикам, у которых подошёл срок
+func (b *Bot) SendDueReviews() {
+	chats, err := b.users.ChatsWithDueCards(time.Now())
+	if err != nil {
+		b.logger.Error("Error retrieving chats with due cards", "error", err)
+		return
+	}
+
+	for chatID, due := range chats {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🧠 Пора повторить термины! К повторению: %d", due))
+		if _, err := b.api.Send(msg); err != nil {
+			b.logger.Error("Error sending review reminder", logging.ChatID, chatID, "error", err)
+			continue
+		}
+		b.sendNextFlashcard(chatID)
+	}
+
+	b.logger.Info("Review sessions started", "chats", len(chats))
+}
+
+// handleVocabularyCallback обрабатывает кнопки словаря и карточек
+func (b *Bot) handleVocabularyCallback(query *tgbotapi.CallbackQuery) {
+	parts := strings.Split(query.Data, ":")
+	if len(parts) < 2 || query.From == nil {
+		b.answerCallback(query, "")
+		return
+	}
+
+	switch parts[1] {
+	case "save":
+		if len(parts) != 3 {
+			break
+		}
+		saved, err := b.users.SaveTerms(query.From.ID, parts[2])
+		if err != nil {
+			b.logger.Error("Error saving terms", "user_id", query.From.ID, "error", err)
+			b.answerCallback(query, "Не удалось сохранить термины, попробуйте позже")
+			return
+		}
+		if saved == 0 {
+			b.answerCallback(query, "Для этой статьи нет терминов")
+			return
+		}
+		b.answerCallback(query, "📚 Термины сохранены. Повторяйте их командой /vocab в личном чате с ботом")
+		return
+
+	case "review":
+		b.answerCallback(query, "")
+		b.sendNextFlashcard(query.From.ID)
+		return
+
+	case "show":
+		if len(parts) != 3 {
+			break
+		}
+		card, ok := b.ownedCard(query, parts[2])
+		if !ok {
+			return
+		}
+		b.answerCallback(query, "")
+		b.showFlashcardAnswer(query.Message, card)
+		return
+
+	case "grade":
+		if len(parts) != 4 {
+			break
+		}
+		card, ok := b.ownedCard(query, parts[2])
+		if !ok {
+			return
+		}
+		quality, err := strconv.Atoi(parts[3])
+		if err != nil || quality < 0 || quality > 5 {
+			break
+		}
+
+		scheduleReview(card, quality, time.Now())
+		if err := b.users.UpdateCard(card); err != nil {
+			b.logger.Error("Error updating card", "card_id", card.ID, "error", err)
+			b.answerCallback(query, "Не удалось сохранить ответ, попробуйте позже")
+			return
+		}
+		b.answerCallback(query, "")
+
+		// Убираем кнопки с карточки и показываем следующую
+		text := fmt.Sprintf("🧠 <b>%s</b> — %s\n\nСледующее повторение через %d дн.",
+			html.EscapeString(card.Term), html.EscapeString(card.Translation), card.Interval)
+		edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+		edit.ParseMode = "HTML"
+		if _, err := b.api.Send(edit); err != nil {
+			b.logger.Error("Error updating flashcard message", "error", err)
+		}
+		b.sendNextFlashcard(card.ChatID)
+		return
+	}
+
+	b.answerCallback(query, "")
+}
+
+// ownedCard загружает карточку из callback и проверяет, что она принадлежит нажавшему пользователю
+func (b *Bot) ownedCard(query *tgbotapi.CallbackQuery, rawID string) (*storage.Card, bool) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || query.Message == nil {
+		b.answerCallback(query, "")
+		return nil, false
+	}
+
+	card, err := b.users.Card(id)
+	if err != nil || card.ChatID != query.From.ID {
+		b.answerCallback(query, "Карточка не найдена")
+		return nil, false
+	}
+
+	return card, true
+}
+
+// sendNextFlashcard отправляет ближайшую карточку к повторению или сообщает об окончании сессии
+func (b *Bot) sendNextFlashcard(chatID int64) {
+	card, err := b.users.NextDueCard(chatID, time.Now())
+	if err != nil {
+		b.logger.Error("Error retrieving next card", logging.ChatID, chatID, "error", err)
+		return
+	}
+
+	var msg tgbotapi.MessageConfig
+	if card == nil {
+		msg = tgbotapi.NewMessage(chatID, "🎉 На сегодня всё! Новые термины появятся в следующих статьях.")
+	} else {
+		msg = tgbotapi.NewMessage(chatID, fmt.Sprintf("🧠 <b>%s</b>\n\nВспомните перевод термина.", html.EscapeString(card.Term)))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👀 Показать перевод", fmt.Sprintf("%s:show:%d", vocabularyCallbackPrefix, card.ID)),
+		))
+	}
+
+	if _, err := b.api.Send(msg); err != nil {
+		b.logger.Error("Error sending flashcard", logging.ChatID, chatID, "error", err)
+	}
+}
+
+// showFlashcardAnswer открывает перевод и предлагает оценить, насколько легко он вспомнился
+func (b *Bot) showFlashcardAnswer(message *tgbotapi.Message, card *storage.Card) {
+	// Кнопки оценок по две в ряд
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, grade := range flashcardGrades {
+		button := tgbotapi.NewInlineKeyboardButtonData(grade.label,
+			fmt.Sprintf("%s:grade:%d:%d", vocabularyCallbackPrefix, card.ID, grade.quality))
+		if i%2 == 0 {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+		} else {
+			rows[len(rows)-1] = append(rows[len(rows)-1], button)
+		}
+	}
+
+	text := fmt.Sprintf("🧠 <b>%s</b> — %s\n\nНасколько легко было вспомнить?",
+		html.EscapeString(card.Term), html.EscapeString(card.Translation))
+	edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, text,
+		tgbotapi.NewInlineKeyboardMarkup(rows...))
+	edit.ParseMode = "HTML"
+
+	if _, err := b.api.Send(edit); err != nil {
+		b.logger.Error("Error showing flashcard answer", "error", err)
+	}
+}
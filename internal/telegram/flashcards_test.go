@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/andrei/goBot/internal/storage"
+)
+
+func TestScheduleReview(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		card            storage.Card
+		quality         int
+		wantRepetitions int
+		wantInterval    int
+		wantEase        float64
+	}{
+		{"new card, quality 5", storage.Card{Ease: 2.5}, 5, 1, 1, 2.6},
+		{"new card, quality 4", storage.Card{Ease: 2.5}, 4, 1, 1, 2.5},
+		{"new card, quality 3", storage.Card{Ease: 2.5}, 3, 1, 1, 2.36},
+		{"new card, quality 2", storage.Card{Ease: 2.5}, 2, 0, 1, 2.18},
+		{"new card, quality 1", storage.Card{Ease: 2.5}, 1, 0, 1, 1.96},
+		{"new card, quality 0", storage.Card{Ease: 2.5}, 0, 0, 1, 1.7},
+		{"second repetition", storage.Card{Repetitions: 1, Interval: 1, Ease: 2.5}, 4, 2, 6, 2.5},
+		{"third repetition multiplies by ease", storage.Card{Repetitions: 2, Interval: 6, Ease: 2.5}, 4, 3, 15, 2.5},
+		{"interval is rounded", storage.Card{Repetitions: 2, Interval: 6, Ease: 2.6}, 5, 3, 16, 2.7},
+		{"lapse resets progress", storage.Card{Repetitions: 3, Interval: 15, Ease: 2.5}, 1, 0, 1, 1.96},
+		{"ease floor on failure", storage.Card{Ease: 1.4}, 0, 0, 1, 1.3},
+		{"ease floor on hard answer", storage.Card{Repetitions: 2, Interval: 6, Ease: 1.3}, 3, 3, 8, 1.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := tt.card
+			scheduleReview(&card, tt.quality, now)
+
+			if card.Repetitions != tt.wantRepetitions {
+				t.Errorf("Repetitions = %d, want %d", card.Repetitions, tt.wantRepetitions)
+			}
+			if card.Interval != tt.wantInterval {
+				t.Errorf("Interval = %d, want %d", card.Interval, tt.wantInterval)
+			}
+			if math.Abs(card.Ease-tt.wantEase) > 1e-9 {
+				t.Errorf("Ease = %v, want %v", card.Ease, tt.wantEase)
+			}
+			if want := now.AddDate(0, 0, tt.wantInterval); !card.DueAt.Equal(want) {
+				t.Errorf("DueAt = %v, want %v", card.DueAt, want)
+			}
+		})
+	}
+}
+
+func TestScheduleReviewProgression(t *testing.T) {
+	card := storage.Card{Ease: 2.5}
+	var intervals []int
+	for range 5 {
+		scheduleReview(&card, 4, time.Now())
+		intervals = append(intervals, card.Interval)
+	}
+
+	want := []int{1, 6, 15, 38, 95}
+	for i := range want {
+		if intervals[i] != want[i] {
+			t.Fatalf("intervals = %v, want %v", intervals, want)
+		}
+	}
+}
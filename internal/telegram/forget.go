@@ -0,0 +1,81 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrei/goBot/internal/logging"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const forgetCallbackPrefix = "fgt"
+
+// handleForgetCommand запрашивает подтверждение удаления всех данных чата.
+// В группе удалить данные могут только администраторы.
+func (b *Bot) handleForgetCommand(message *tgbotapi.Message) {
+	if !message.Chat.IsPrivate() && !b.isChatAdmin(message.Chat.ID, message.From) {
+		b.replyInChat(message, "Удалить данные группы могут только администраторы.")
+		return
+	}
+
+	text := "Удалить все данные этого чата? Будут удалены подписка и её настройки, отзывы, " +
+		"личный словарь с прогрессом повторения и результаты викторин. Действие нельзя отменить."
+	if !message.Chat.IsPrivate() {
+		text = "Удалить все данные этой группы? Будут удалены подписка и её настройки, отзывы " +
+			"и результаты викторин группы. Действие нельзя отменить."
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить всё", forgetCallbackPrefix+":confirm"),
+			tgbotapi.NewInlineKeyboardButtonData("Отмена", forgetCallbackPrefix+":cancel"),
+		),
+	)
+	if !message.Chat.IsPrivate() {
+		msg.ReplyToMessageID = message.MessageID
+	}
+
+	if _, err := b.api.Send(msg); err != nil {
+		b.logger.Error("Error sending forget confirmation", logging.ChatID, message.Chat.ID, "error", err)
+	}
+}
+
+// handleForgetCallback удаляет данные чата после подтверждения
+func (b *Bot) handleForgetCallback(query *tgbotapi.CallbackQuery) {
+	if query.Message == nil || query.From == nil {
+		b.answerCallback(query, "")
+		return
+	}
+
+	chat := query.Message.Chat
+	// Кнопку в группе может нажать любой участник, поэтому права проверяются ещё раз
+	if !chat.IsPrivate() && !b.isChatAdmin(chat.ID, query.From) {
+		b.answerCallback(query, "Удалить данные группы могут только администраторы")
+		return
+	}
+
+	var text string
+	switch strings.TrimPrefix(query.Data, forgetCallbackPrefix+":") {
+	case "confirm":
+		deleted, err := b.users.Forget(chat.ID, query.From.ID)
+		if err != nil {
+			b.logger.Error("Error deleting chat data", logging.ChatID, chat.ID, "error", err)
+			b.answerCallback(query, "Не удалось удалить данные, попробуйте позже")
+			return
+		}
+		text = fmt.Sprintf("Данные удалены (записей: %d). Чтобы снова получать новости, отправьте /start.", deleted.Total())
+	case "cancel":
+		text = "Удаление отменено, данные сохранены."
+	default:
+		b.answerCallback(query, "")
+		return
+	}
+
+	b.answerCallback(query, "")
+
+	edit := tgbotapi.NewEditMessageText(chat.ID, query.Message.MessageID, text)
+	if _, err := b.api.Send(edit); err != nil {
+		b.logger.Error("Error updating forget confirmation", logging.ChatID, chat.ID, "error", err)
+	}
+}
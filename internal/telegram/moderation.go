@@ -0,0 +1,336 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/news"
+	"github.com/andrei/goBot/internal/summarizer"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const moderationCallbackPrefix = "mod"
+
+// Действия редактора над черновиком рассылки
+const (
+	moderationApprove    = "approve"
+	moderationSkip       = "skip"
+	moderationRegenerate = "regen"
+	moderationEdit       = "edit"
+	moderationText       = "text"
+)
+
+// ErrNothingApproved возвращается, если ни один кандидат не был опубликован
+var ErrNothingApproved = errors.New("no candidate was approved for broadcast")
+
+// SummarizeFunc генерирует сводку для статьи; модерация вызывает её повторно при перегенерации
+type SummarizeFunc func(ctx context.Context, article *news.Article) (*summarizer.Summary, error)
+
+// ModerationConfig задаёт чат редакторов и время ожидания решения
+type ModerationConfig struct {
+	EditorsChatID int64
+	// Timeout — время, после которого текущий черновик публикуется автоматически
+	Timeout time.Duration
+}
+
+type reviewAction struct {
+	kind   string
+	text   string
+	editor string
+}
+
+// review хранит состояние модерации одной рассылки
+type review struct {
+	id            string
+	editorsChatID int64
+	actions       chan reviewAction
+
+	mu        sync.Mutex
+	messageID int
+	promptID  int
+}
+
+// draft — статья, которую редакторы видят в данный момент
+type draft struct {
+	index   int
+	article *news.Article
+	summary *summarizer.Summary
+	text    string
+	edited  bool
+}
+
+// Moderate отправляет кандидатов в чат редакторов и рассылает статью только после
+// одобрения либо автоматически по истечении cfg.Timeout. Блокирует до принятия решения.
+func (b *Bot) Moderate(ctx context.Context, cfg ModerationConfig, candidates []*news.Article, summarize SummarizeFunc) error {
+	if len(candidates) == 0 {
+		return fmt.Errorf("no candidates to moderate")
+	}
+
+	r := &review{
+		id:            strconv.FormatInt(time.Now().UnixNano(), 36),
+		editorsChatID: cfg.EditorsChatID,
+		actions:       make(chan reviewAction, 1),
+	}
+
+	b.reviewsMu.Lock()
+	b.reviews[r.id] = r
+	b.reviewsMu.Unlock()
+
+	defer func() {
+		b.reviewsMu.Lock()
+		delete(b.reviews, r.id)
+		b.reviewsMu.Unlock()
+	}()
+
+	deadline := time.NewTimer(cfg.Timeout)
+	defer deadline.Stop()
+
+	current, err := b.nextDraft(ctx, r, candidates, 0, summarize)
+	if err != nil {
+		return err
+	}
+	if err := b.showDraft(r, current, len(candidates)); err != nil {
+		return fmt.Errorf("failed to send draft to editors: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-deadline.C:
+			b.logger.InfoContext(ctx, "Moderation timeout reached, auto-publishing article", logging.ArticleURL, current.article.URL)
+			b.notifyEditors(r, fmt.Sprintf("⏰ Решение не принято за %s, статья опубликована автоматически.", cfg.Timeout))
+			return b.broadcast(ctx, current.article, current.summary, current.text)
+
+		case action := <-r.actions:
+			switch action.kind {
+			case moderationApprove:
+				b.logger.InfoContext(ctx, "Article approved", "editor", action.editor, logging.ArticleURL, current.article.URL)
+				b.notifyEditors(r, fmt.Sprintf("✅ %s одобрил(а) публикацию.", action.editor))
+				return b.broadcast(ctx, current.article, current.summary, current.text)
+
+			case moderationSkip:
+				b.logger.InfoContext(ctx, "Article skipped", "editor", action.editor, logging.ArticleURL, current.article.URL)
+				next, err := b.nextDraft(ctx, r, candidates, current.index+1, summarize)
+				if err != nil {
+					return err
+				}
+				current = next
+				if err := b.showDraft(r, current, len(candidates)); err != nil {
+					b.logger.ErrorContext(ctx, "Error updating draft in editors chat", "error", err)
+				}
+
+			case moderationRegenerate:
+				summary, err := summarize(ctx, current.article)
+				if err != nil {
+					b.logger.ErrorContext(ctx, "Error regenerating summary", logging.ArticleURL, current.article.URL, "error", err)
+					b.notifyEditors(r, fmt.Sprintf("⚠️ Не удалось перегенерировать сводку: %v", err))
+					continue
+				}
+				current.summary = summary
+				current.text = render(ctx, current.article, summary)
+				current.edited = false
+				if err := b.showDraft(r, current, len(candidates)); err != nil {
+					b.logger.ErrorContext(ctx, "Error updating draft in editors chat", "error", err)
+				}
+
+			case moderationEdit:
+				b.requestEditedText(r)
+
+			case moderationText:
+				previous := current.text
+				current.text = action.text
+				current.edited = true
+				if err := b.showDraft(r, current, len(candidates)); err != nil {
+					// Скорее всего, в тексте некорректная HTML-разметка
+					current.text = previous
+					b.notifyEditors(r, fmt.Sprintf("⚠️ Не удалось применить текст: %v", err))
+				}
+			}
+		}
+	}
+}
+
+// nextDraft готовит черновик для первого кандидата, начиная с index, для которого удалось получить сводку
+func (b *Bot) nextDraft(ctx context.Context, r *review, candidates []*news.Article, index int, summarize SummarizeFunc) (*draft, error) {
+	for ; index < len(candidates); index++ {
+		article := candidates[index]
+		summary, err := summarize(ctx, article)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			b.logger.WarnContext(ctx, "Error processing candidate", logging.ArticleURL, article.URL, "error", err)
+			continue
+		}
+
+		return &draft{
+			index:   index,
+			article: article,
+			summary: summary,
+			text:    render(ctx, article, summary),
+		}, nil
+	}
+
+	b.notifyEditors(r, "🚫 Кандидаты закончились, рассылка отменена.")
+	return nil, ErrNothingApproved
+}
+
+// showDraft отправляет черновик в чат редакторов или обновляет уже отправленный
+func (b *Bot) showDraft(r *review, d *draft, total int) error {
+	var header strings.Builder
+	header.WriteString(fmt.Sprintf("📝 <b>Кандидат %d из %d</b>", d.index+1, total))
+	if d.edited {
+		header.WriteString(" (текст изменён)")
+	}
+	if len(d.article.Coverage) > 0 {
+		header.WriteString("\nТакже пишут: " + html.EscapeString(strings.Join(d.article.Coverage, ", ")))
+	}
+	header.WriteString("\n\n")
+	text := header.String() + d.text
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Опубликовать", moderationCallbackData(moderationApprove, r.id)),
+			tgbotapi.NewInlineKeyboardButtonData("⏭ Следующий", moderationCallbackData(moderationSkip, r.id)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Перегенерировать", moderationCallbackData(moderationRegenerate, r.id)),
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить текст", moderationCallbackData(moderationEdit, r.id)),
+		),
+	)
+
+	r.mu.Lock()
+	messageID := r.messageID
+	r.mu.Unlock()
+
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(r.editorsChatID, messageID, text, keyboard)
+		edit.ParseMode = "HTML"
+		_, err := b.api.Send(edit)
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(r.editorsChatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.messageID = sent.MessageID
+	r.mu.Unlock()
+	return nil
+}
+
+// requestEditedText просит редактора прислать новый текст ответом на сообщение
+func (b *Bot) requestEditedText(r *review) {
+	msg := tgbotapi.NewMessage(r.editorsChatID, "✏️ Пришлите новый текст статьи ответом на это сообщение. Форматирование сохраняется; без него можно использовать HTML-разметку Telegram.")
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, InputFieldPlaceholder: "Новый текст статьи"}
+
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		b.logger.Error("Error requesting edited text", "error", err)
+		return
+	}
+
+	r.mu.Lock()
+	r.promptID = sent.MessageID
+	r.mu.Unlock()
+}
+
+func (b *Bot) notifyEditors(r *review, text string) {
+	if _, err := b.api.Send(tgbotapi.NewMessage(r.editorsChatID, text)); err != nil {
+		b.logger.Error("Error notifying editors", "error", err)
+	}
+}
+
+// handleModerationCallback передаёт нажатие кнопки редактором в соответствующую модерацию
+func (b *Bot) handleModerationCallback(query *tgbotapi.CallbackQuery) {
+	parts := strings.SplitN(query.Data, ":", 3)
+	if len(parts) != 3 {
+		b.answerCallback(query, "")
+		return
+	}
+	action, reviewID := parts[1], parts[2]
+
+	b.reviewsMu.Lock()
+	r, ok := b.reviews[reviewID]
+	b.reviewsMu.Unlock()
+
+	if !ok {
+		b.answerCallback(query, "Модерация уже завершена")
+		return
+	}
+	if query.Message == nil || query.Message.Chat.ID != r.editorsChatID {
+		b.answerCallback(query, "Действие недоступно")
+		return
+	}
+
+	if !b.sendReviewAction(r, reviewAction{kind: action, editor: editorName(query.From)}) {
+		b.answerCallback(query, "Предыдущее действие ещё обрабатывается")
+		return
+	}
+	b.answerCallback(query, "")
+}
+
+// handleModerationReply принимает отредактированный текст; возвращает true, если сообщение обработано
+func (b *Bot) handleModerationReply(message *tgbotapi.Message) bool {
+	if message.ReplyToMessage == nil || message.Text == "" {
+		return false
+	}
+
+	b.reviewsMu.Lock()
+	defer b.reviewsMu.Unlock()
+
+	for _, r := range b.reviews {
+		r.mu.Lock()
+		matches := r.editorsChatID == message.Chat.ID && r.promptID != 0 && r.promptID == message.ReplyToMessage.MessageID
+		r.mu.Unlock()
+
+		if matches {
+			// Форматирование, сделанное в клиенте Telegram, приходит в entities, а не в тексте
+			text := entitiesHTML(message.Text, message.Entities)
+			if !b.sendReviewAction(r, reviewAction{kind: moderationText, text: text, editor: editorName(message.From)}) {
+				b.notifyEditors(r, "Предыдущее действие ещё обрабатывается, попробуйте ещё раз.")
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// sendReviewAction не блокирует обработку обновлений, если модерация занята предыдущим действием
+func (b *Bot) sendReviewAction(r *review, action reviewAction) bool {
+	select {
+	case r.actions <- action:
+		return true
+	default:
+		return false
+	}
+}
+
+func moderationCallbackData(action, reviewID string) string {
+	return moderationCallbackPrefix + ":" + action + ":" + reviewID
+}
+
+func editorName(user *tgbotapi.User) string {
+	if user == nil {
+		return "unknown"
+	}
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+	return user.FirstName
+}
@@ -0,0 +1,112 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/andrei/goBot/internal/logging"
+	"github.com/andrei/goBot/internal/storage"
+	"github.com/andrei/goBot/internal/summarizer"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sendQuiz отправляет вопросы по статье нативными опросами-викторинами.
+// В каналах опросы могут быть только анонимными, поэтому ответы там не учитываются.
+func (b *Bot) sendQuiz(ctx context.Context, target storage.Target, articleID string, quiz []summarizer.QuizQuestion) {
+	anonymous := target.Type == "channel"
+
+	for _, question := range quiz {
+		options, err := json.Marshal(question.Options)
+		if err != nil {
+			b.logger.ErrorContext(ctx, "Error encoding quiz options", "error", err)
+			continue
+		}
+
+		params := tgbotapi.Params{}
+		params.AddNonZero64("chat_id", target.ChatID)
+		params.AddNonEmpty("question", question.Question)
+		params.AddNonEmpty("options", string(options))
+		params.AddNonEmpty("type", "quiz")
+		params["correct_option_id"] = fmt.Sprint(question.Correct)
+		params["is_anonymous"] = fmt.Sprint(anonymous)
+		params.AddNonEmpty("explanation", question.Explanation)
+		params.AddNonZero("message_thread_id", target.ThreadID)
+		params.AddBool("disable_notification", target.Silent)
+
+		resp, err := b.api.MakeRequest("sendPoll", params)
+		if err != nil {
+			b.logger.ErrorContext(ctx, "Error sending quiz", "chat_type", target.Type, logging.ChatID, target.ChatID, "error", err)
+			return
+		}
+		if anonymous {
+			continue
+		}
+
+		var sent tgbotapi.Message
+		if err := json.Unmarshal(resp.Result, &sent); err != nil || sent.Poll == nil {
+			b.logger.ErrorContext(ctx, "Error decoding sent quiz", logging.ChatID, target.ChatID, "error", err)
+			continue
+		}
+		b.users.RecordQuizPoll(sent.Poll.ID, target.ChatID, articleID, question.Correct)
+	}
+}
+
+// handlePollAnswer засчитывает ответ на викторину
+func (b *Bot) handlePollAnswer(answer *tgbotapi.PollAnswer) {
+	// В викторине нельзя отозвать голос, пустой ответ не обрабатываем
+	if len(answer.OptionIDs) == 0 {
+		return
+	}
+
+	name := answer.User.FirstName
+	if answer.User.UserName != "" {
+		name = "@" + answer.User.UserName
+	}
+
+	if err := b.users.RecordQuizAnswer(answer.PollID, answer.User.ID, name, answer.OptionIDs[0]); err != nil {
+		b.logger.Error("Error recording quiz answer", "user_id", answer.User.ID, "error", err)
+	}
+}
+
+// handleScoreCommand показывает таблицу лидеров: в группе — по викторинам этой группы
+func (b *Bot) handleScoreCommand(message *tgbotapi.Message) {
+	var chatID int64
+	if !message.Chat.IsPrivate() {
+		chatID = message.Chat.ID
+	}
+
+	leaders, err := b.users.Leaderboard(chatID, 10)
+	if err != nil {
+		b.logger.Error("Error retrieving leaderboard", "error", err)
+		b.replyInChat(message, "Не удалось загрузить таблицу лидеров, попробуйте позже.")
+		return
+	}
+	if len(leaders) == 0 {
+		b.replyInChat(message, "Пока никто не отвечал на викторины. Вопросы приходят после каждой статьи!")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<b>🏆 Таблица лидеров:</b>\n\n")
+	for i, score := range leaders {
+		sb.WriteString(fmt.Sprintf("%d. %s — %d из %d\n", i+1, html.EscapeString(score.UserName), score.Correct, score.Total))
+	}
+
+	if message.From != nil {
+		if own, err := b.users.UserScore(chatID, message.From.ID); err == nil && own.Total > 0 {
+			sb.WriteString(fmt.Sprintf("\nВаш результат: %d из %d", own.Correct, own.Total))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, sb.String())
+	msg.ParseMode = "HTML"
+	if !message.Chat.IsPrivate() {
+		msg.ReplyToMessageID = message.MessageID
+	}
+	if _, err := b.api.Send(msg); err != nil {
+		b.logger.Error("Error sending leaderboard", logging.ChatID, message.Chat.ID, "error", err)
+	}
+}
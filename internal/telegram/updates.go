@@ -0,0 +1,84 @@
+package telegram
+
+import (
+	"encoding/json"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// update дополняет tgbotapi.Update полями Bot API, которых нет в библиотеке
+type update struct {
+	tgbotapi.Update
+	// ThreadID — тема форума, в которой отправлено сообщение; 0 вне тем
+	ThreadID int
+}
+
+// topicInfo извлекает из сырого обновления информацию о теме форума
+type topicInfo struct {
+	Message *struct {
+		MessageThreadID int  `json:"message_thread_id"`
+		IsTopicMessage  bool `json:"is_topic_message"`
+	} `json:"message"`
+}
+
+// allowedUpdates перечисляет типы обновлений, которые обрабатывает бот
+var allowedUpdates = []string{
+	tgbotapi.UpdateTypeMessage,
+	tgbotapi.UpdateTypeCallbackQuery,
+	tgbotapi.UpdateTypeMyChatMember,
+	tgbotapi.UpdateTypePollAnswer,
+}
+
+// getUpdatesChan повторяет tgbotapi.GetUpdatesChan, но сохраняет message_thread_id,
+// который библиотека при разборе обновлений отбрасывает
+func (b *Bot) getUpdatesChan(config tgbotapi.UpdateConfig) <-chan update {
+	ch := make(chan update, b.api.Buffer)
+
+	go func() {
+		for {
+			params := tgbotapi.Params{}
+			params.AddNonZero("offset", config.Offset)
+			params.AddNonZero("limit", config.Limit)
+			params.AddNonZero("timeout", config.Timeout)
+			params.AddInterface("allowed_updates", allowedUpdates)
+
+			resp, err := b.api.MakeRequest("getUpdates", params)
+			if err != nil {
+				b.logger.Warn("Failed to get updates, retrying in 3 seconds", "error", err)
+				time.Sleep(3 * time.Second)
+				continue
+			}
+
+			var raw []json.RawMessage
+			if err := json.Unmarshal(resp.Result, &raw); err != nil {
+				b.logger.Error("Error decoding updates, retrying in 3 seconds", "error", err)
+				time.Sleep(3 * time.Second)
+				continue
+			}
+
+			for _, data := range raw {
+				var upd update
+				err := json.Unmarshal(data, &upd.Update)
+				if upd.UpdateID < config.Offset {
+					continue
+				}
+				// Смещаем offset даже для нераспознанных обновлений, чтобы не получать их повторно
+				config.Offset = upd.UpdateID + 1
+				if err != nil {
+					b.logger.Error("Error decoding update", "update_id", upd.UpdateID, "error", err)
+					continue
+				}
+
+				var topic topicInfo
+				if err := json.Unmarshal(data, &topic); err == nil && topic.Message != nil && topic.Message.IsTopicMessage {
+					upd.ThreadID = topic.Message.MessageThreadID
+				}
+
+				ch <- upd
+			}
+		}
+	}()
+
+	return ch
+}